@@ -3,12 +3,10 @@ package auth
 import (
 	"errors"
 	"net/http"
-	"time"
 
 	"github.com/aukbit/pluto/v6"
 	"github.com/aukbit/pluto/v6/auth/jwt"
 	pba "github.com/aukbit/pluto/v6/auth/proto"
-	"github.com/aukbit/pluto/v6/client"
 	"github.com/aukbit/pluto/v6/reply"
 	"github.com/aukbit/pluto/v6/server/router"
 )
@@ -27,22 +25,14 @@ func MiddlewareBearerAuth() router.Middleware {
 			}
 			// verify if token is valid with Auth backend service
 			ctx := r.Context()
-			// get gRPC Auth Client from pluto service context
-			c, ok := pluto.FromContext(ctx).Client("auth")
-			if !ok {
-				err := errors.New("Authorization service not available")
-				reply.Json(w, r, http.StatusInternalServerError, err.Error())
-				return
-			}
-			// dial
-			conn, err := c.Dial(client.Timeout(5 * time.Second))
+			// get gRPC Auth stub from pluto service context
+			stub, err := pluto.Stub[pba.AuthServiceClient](ctx, "auth")
 			if err != nil {
 				reply.Json(w, r, http.StatusInternalServerError, err.Error())
 				return
 			}
-			defer conn.Close()
 			// make a call to the Auth backend service
-			v, err := c.Stub(conn).(pba.AuthServiceClient).Verify(ctx, &pba.Token{Jwt: t})
+			v, err := stub.Verify(ctx, &pba.Token{Jwt: t})
 			if err != nil {
 				reply.Json(w, r, http.StatusUnauthorized, err.Error())
 				return
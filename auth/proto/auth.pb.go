@@ -5,6 +5,7 @@ package auth
 
 import (
 	fmt "fmt"
+	_ "github.com/aukbit/pluto/v6/redact/proto"
 	proto "github.com/golang/protobuf/proto"
 	context "golang.org/x/net/context"
 	grpc "google.golang.org/grpc"
@@ -159,21 +160,22 @@ func init() {
 func init() { proto.RegisterFile("auth/proto/auth.proto", fileDescriptor_bbb77c39afec69ef) }
 
 var fileDescriptor_bbb77c39afec69ef = []byte{
-	// 210 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x90, 0x41, 0x4e, 0x85, 0x30,
-	0x10, 0x40, 0xff, 0x57, 0x41, 0x1c, 0x58, 0xe8, 0x44, 0x13, 0x64, 0x65, 0xba, 0x22, 0x31, 0x01,
-	0xa3, 0x07, 0x30, 0xc6, 0x1b, 0xa0, 0x61, 0x5f, 0x61, 0x0c, 0xa3, 0x95, 0x92, 0xb6, 0x48, 0xbc,
-	0xbd, 0x69, 0xd1, 0x1f, 0x76, 0xef, 0x75, 0xda, 0xcc, 0x4b, 0xe1, 0x4a, 0xce, 0x6e, 0xa8, 0x27,
-	0xa3, 0x9d, 0xae, 0x3d, 0x56, 0x01, 0xf1, 0xc4, 0xb3, 0x78, 0x84, 0xf4, 0xd9, 0x50, 0x4f, 0xa3,
-	0x63, 0xa9, 0x2c, 0x5e, 0x42, 0x44, 0x5f, 0x92, 0x55, 0xbe, 0xbf, 0xd9, 0x97, 0x67, 0xcd, 0x2a,
-	0x58, 0x40, 0x32, 0x49, 0x6b, 0x17, 0x6d, 0xfa, 0xfc, 0x28, 0x0c, 0x0e, 0x2e, 0xae, 0x21, 0x7a,
-	0xd5, 0x9f, 0x34, 0xe2, 0x39, 0x1c, 0x7f, 0x2c, 0xee, 0xef, 0xa1, 0x47, 0x51, 0x42, 0xd6, 0x92,
-	0xe1, 0x77, 0xee, 0xa4, 0x63, 0x3d, 0x62, 0x0e, 0xa7, 0x6c, 0x5b, 0xa9, 0xb8, 0x0f, 0xb7, 0x92,
-	0xe6, 0x5f, 0xef, 0x15, 0xa4, 0x4f, 0xb3, 0x1b, 0x5e, 0xc8, 0x7c, 0x73, 0x47, 0x78, 0x07, 0x99,
-	0x57, 0x1f, 0xd5, 0x49, 0x47, 0x78, 0x51, 0x85, 0xee, 0x4d, 0x68, 0x91, 0xae, 0x47, 0x61, 0xb5,
-	0xd8, 0xe1, 0x2d, 0xc4, 0x61, 0xd5, 0x0f, 0x6e, 0x07, 0x05, 0xae, 0xb2, 0xad, 0x10, 0xbb, 0xb7,
-	0x38, 0x7c, 0xc0, 0xc3, 0x6f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x83, 0x61, 0xeb, 0x73, 0x19, 0x01,
-	0x00, 0x00,
+	// 230 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x90, 0x41, 0x4e, 0xc3, 0x30,
+	0x10, 0x45, 0x1b, 0x68, 0x4b, 0x99, 0x74, 0x01, 0x23, 0x90, 0xd2, 0xac, 0x2a, 0xaf, 0x2a, 0x21,
+	0xb5, 0x08, 0x4e, 0x00, 0x88, 0x0b, 0x04, 0xd4, 0xfd, 0x90, 0x0c, 0xea, 0x40, 0x88, 0x2b, 0x7b,
+	0x4a, 0xc5, 0xed, 0x38, 0x07, 0xa7, 0x41, 0xb6, 0x0b, 0xca, 0xee, 0xbf, 0xf9, 0x63, 0xfd, 0xef,
+	0x81, 0x4b, 0xda, 0xe9, 0x66, 0xb5, 0x75, 0x56, 0xed, 0x2a, 0xc8, 0x65, 0x94, 0x38, 0x0c, 0xba,
+	0x9c, 0x39, 0x6e, 0xa8, 0xd6, 0x83, 0x9d, 0x20, 0x2d, 0x98, 0x47, 0xc8, 0x1f, 0x1c, 0x37, 0xdc,
+	0xa9, 0x50, 0xeb, 0xf1, 0x02, 0x46, 0xfc, 0x41, 0xd2, 0x16, 0xd9, 0x3c, 0x5b, 0x9c, 0x56, 0x09,
+	0x70, 0x0e, 0x93, 0x2d, 0x79, 0xbf, 0xb7, 0xae, 0x29, 0x8e, 0x82, 0x71, 0x3f, 0xfc, 0xfe, 0x29,
+	0xb2, 0xea, 0x7f, 0x6a, 0x66, 0x30, 0x7a, 0xb6, 0xef, 0xdc, 0xe1, 0x19, 0x1c, 0xbf, 0xed, 0xf5,
+	0xf0, 0x3c, 0x48, 0xb3, 0x80, 0xe9, 0x9a, 0x9d, 0xbc, 0x4a, 0x4d, 0x2a, 0xb6, 0xc3, 0x02, 0x4e,
+	0xc4, 0xaf, 0xa9, 0x95, 0x26, 0x6e, 0x4d, 0xaa, 0x3f, 0xbc, 0x69, 0x21, 0xbf, 0xdb, 0xe9, 0xe6,
+	0x89, 0xdd, 0xa7, 0xd4, 0x8c, 0xd7, 0x30, 0x0d, 0x18, 0xaa, 0xd5, 0xa4, 0x8c, 0xe7, 0xcb, 0xf8,
+	0xb1, 0x5e, 0xdd, 0x32, 0x4f, 0xa3, 0x18, 0x6d, 0x06, 0x78, 0x05, 0xe3, 0x18, 0xf5, 0x85, 0x7d,
+	0xa3, 0xc4, 0x04, 0xfd, 0x16, 0x66, 0xf0, 0x32, 0x8e, 0x07, 0xb8, 0xfd, 0x1d, 0x00, 0x50, 0xa0,
+	0x0f, 0x35, 0x3a, 0x01, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -0,0 +1,175 @@
+// Package certificate provides a TLS key pair reloaded from its files while
+// in use, so certificates can be rotated without restarting servers or
+// clients.
+package certificate
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/net/context"
+)
+
+var (
+	defaultInterval      = time.Minute
+	defaultExpiryWarning = 24 * time.Hour
+)
+
+// Source is a TLS key pair loaded from files and swapped atomically when
+// they change. Use GetCertificate in server and GetClientCertificate in
+// client TLS configs.
+type Source struct {
+	certFile      string
+	keyFile       string
+	interval      time.Duration // how often files are checked for changes
+	expiryWarning time.Duration // time before expiry the certificate is reported as expiring
+	logger        zerolog.Logger
+	cert          atomic.Value // *tls.Certificate
+	mu            sync.Mutex   // serializes reloads; protects the following fields
+	certPEM       []byte       // files content currently loaded
+	keyPEM        []byte
+}
+
+// Status of the certificate loaded
+type Status struct {
+	Subject  string    `json:"subject"`
+	NotAfter time.Time `json:"not_after"`
+	Expiring bool      `json:"expiring"` // expires within the expiry warning
+	Expired  bool      `json:"expired"`
+}
+
+// NewSource returns a source with the key pair loaded from certFile and
+// keyFile
+func NewSource(certFile, keyFile string, opts ...Option) (*Source, error) {
+	s := &Source{
+		certFile:      certFile,
+		keyFile:       keyFile,
+		interval:      defaultInterval,
+		expiryWarning: defaultExpiryWarning,
+		logger:        zerolog.New(os.Stderr).With().Timestamp().Logger(),
+	}
+	for _, opt := range opts {
+		opt.apply(s)
+	}
+	s.logger = s.logger.With().Str("certificate", certFile).Logger()
+	if _, err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetCertificate returns the certificate loaded, to be set as
+// tls.Config.GetCertificate of servers
+func (s *Source) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return s.Certificate(), nil
+}
+
+// GetClientCertificate returns the certificate loaded, to be set as
+// tls.Config.GetClientCertificate of clients
+func (s *Source) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return s.Certificate(), nil
+}
+
+// Certificate returns the certificate loaded
+func (s *Source) Certificate() *tls.Certificate {
+	return s.cert.Load().(*tls.Certificate)
+}
+
+// Status returns the status of the certificate loaded
+func (s *Source) Status() Status {
+	leaf := s.Certificate().Leaf
+	left := time.Until(leaf.NotAfter)
+	return Status{
+		Subject:  leaf.Subject.String(),
+		NotAfter: leaf.NotAfter,
+		Expiring: left < s.expiryWarning,
+		Expired:  left <= 0,
+	}
+}
+
+// CheckExpiry logs a warning if the certificate loaded is expiring and an
+// error if it has expired, and returns its status
+func (s *Source) CheckExpiry() Status {
+	st := s.Status()
+	switch {
+	case st.Expired:
+		s.logger.Error().Time("not_after", st.NotAfter).
+			Msg(fmt.Sprintf("certificate %s expired on %v", st.Subject, st.NotAfter))
+	case st.Expiring:
+		s.logger.Warn().Time("not_after", st.NotAfter).
+			Msg(fmt.Sprintf("certificate %s expires in %v", st.Subject, time.Until(st.NotAfter).Round(time.Second)))
+	}
+	return st
+}
+
+// Reload loads the key pair from its files if they have changed. On error,
+// the certificate previously loaded is kept.
+func (s *Source) Reload() error {
+	changed, err := s.reload()
+	if err != nil {
+		s.logger.Error().Msg(fmt.Sprintf("certificate reload failed, keeping the one loaded: %v", err))
+		return err
+	}
+	if changed {
+		st := s.CheckExpiry()
+		s.logger.Info().Time("not_after", st.NotAfter).
+			Msg(fmt.Sprintf("certificate %s reloaded", st.Subject))
+	}
+	return nil
+}
+
+// Watch reloads the key pair whenever its files change, checked every
+// interval, or the process receives SIGHUP, until ctx is done
+func (s *Source) Watch(ctx context.Context) {
+	sigch := make(chan os.Signal, 1)
+	signal.Notify(sigch, syscall.SIGHUP)
+	defer signal.Stop(sigch)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigch:
+		case <-ticker.C:
+		}
+		s.Reload()
+	}
+}
+
+// reload loads the key pair and reports whether it has changed
+func (s *Source) reload() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	certPEM, err := ioutil.ReadFile(s.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyPEM, err := ioutil.ReadFile(s.keyFile)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(certPEM, s.certPEM) && bytes.Equal(keyPEM, s.keyPEM) {
+		return false, nil
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, err
+	}
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return false, err
+	}
+	s.cert.Store(&cert)
+	s.certPEM, s.keyPEM = certPEM, keyPEM
+	return true, nil
+}
@@ -0,0 +1,103 @@
+package certificate_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aukbit/pluto/v6/certificate"
+	"github.com/paulormart/assert"
+	"golang.org/x/net/context"
+)
+
+// writeKeyPair writes a self signed key pair for cn, valid for d
+func writeKeyPair(t *testing.T, dir, cn string, d time.Duration) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(d),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kder, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir, "v1", 30*24*time.Hour)
+	src, err := certificate.NewSource(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := src.GetCertificate(nil)
+	assert.Equal(t, "v1", cert.Leaf.Subject.CommonName)
+	assert.Equal(t, false, src.Status().Expiring)
+	// files rotated
+	writeKeyPair(t, dir, "v2", time.Hour)
+	assert.Equal(t, nil, src.Reload())
+	cert, _ = src.GetClientCertificate(nil)
+	assert.Equal(t, "v2", cert.Leaf.Subject.CommonName)
+	st := src.Status()
+	assert.Equal(t, "CN=v2", st.Subject)
+	assert.Equal(t, true, st.Expiring)
+	assert.Equal(t, false, st.Expired)
+	// invalid files keep the certificate loaded
+	if err := ioutil.WriteFile(keyFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, src.Reload() != nil)
+	assert.Equal(t, "v2", src.Certificate().Leaf.Subject.CommonName)
+	// missing files are reported by NewSource
+	_, err = certificate.NewSource(filepath.Join(dir, "missing.crt"), keyFile)
+	assert.Equal(t, true, err != nil)
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir, "v1", time.Hour)
+	src, err := certificate.NewSource(certFile, keyFile, certificate.Interval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go src.Watch(ctx)
+	time.Sleep(50 * time.Millisecond)
+	writeKeyPair(t, dir, "v2", time.Hour)
+	// reloaded on SIGHUP, well before the interval
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for src.Certificate().Leaf.Subject.CommonName != "v2" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, "v2", src.Certificate().Leaf.Subject.CommonName)
+}
@@ -0,0 +1,42 @@
+package certificate
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Option is used to set options for the source.
+type Option interface {
+	apply(*Source)
+}
+
+// optionFunc wraps a func so it satisfies the Option interface.
+type optionFunc func(*Source)
+
+func (f optionFunc) apply(s *Source) {
+	f(s)
+}
+
+// Interval sets how often the files are checked for changes.
+// The default is one minute.
+func Interval(d time.Duration) Option {
+	return optionFunc(func(s *Source) {
+		s.interval = d
+	})
+}
+
+// ExpiryWarning sets the time before expiry the certificate is reported
+// as expiring. The default is 24 hours.
+func ExpiryWarning(d time.Duration) Option {
+	return optionFunc(func(s *Source) {
+		s.expiryWarning = d
+	})
+}
+
+// Logger sets a shallow copy from an input logger
+func Logger(l zerolog.Logger) Option {
+	return optionFunc(func(s *Source) {
+		s.logger = l
+	})
+}
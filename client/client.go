@@ -3,11 +3,16 @@ package client
 import (
 	"fmt"
 	"os"
+	"reflect"
+	"sync"
 
+	"github.com/aukbit/pluto/v6/common"
 	"github.com/rs/zerolog"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
@@ -20,9 +25,13 @@ const (
 // A Client defines parameters for making calls to an HTTP server.
 // The zero value for Client is a valid configuration.
 type Client struct {
-	cfg    Config
-	health *health.Server
-	logger zerolog.Logger
+	cfg     Config
+	mu      sync.Mutex         // protects conn and unwatch
+	conn    *grpc.ClientConn   // shared gRPC channel, lazily created by Conn
+	unwatch context.CancelFunc // stops reloading certificates, set by Init
+	health  *health.Server
+	logger  zerolog.Logger
+	errs    common.Errors // errors recorded while applying options
 }
 
 // New create a new client
@@ -70,20 +79,85 @@ func (c *Client) Init(opts ...Option) {
 	c.logger.Info().Msg(fmt.Sprintf("starting %s %s, connecting to %s", c.cfg.Format, c.Name(), c.cfg.Target))
 	// append dial interceptor to grpc client
 	c.cfg.mu.Lock()
-	defer c.cfg.mu.Unlock()
 	c.cfg.UnaryClientInterceptors = append(c.cfg.UnaryClientInterceptors, dialUnaryClientInterceptor(c))
 	c.cfg.StreamClientInterceptors = append(c.cfg.StreamClientInterceptors, dialStreamClientInterceptor(c))
+	c.cfg.mu.Unlock()
+	if c.cfg.Certificates != nil {
+		c.watchCertificates()
+	}
+	// warm up shared connection
+	if _, err := c.Conn(); err != nil {
+		c.logger.Error().Msg(err.Error())
+	}
+}
+
+// watchCertificates reloads the certificates on change until Close
+func (c *Client) watchCertificates() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unwatch != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.unwatch = cancel
+	go c.cfg.Certificates.Watch(ctx)
+}
+
+// Conn returns the gRPC channel shared by all calls made through this client.
+// The channel is created on first use and recreated if it has been shut
+// down; in between, gRPC reconnects to the target transparently.
+// Callers must not close the returned connection, use Close instead.
+func (c *Client) Conn() (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil && c.conn.GetState() != connectivity.Shutdown {
+		return c.conn, nil
+	}
+	conn, err := grpc.Dial(
+		c.cfg.Target,
+		c.transportCredentials(),
+		grpc.WithUnaryInterceptor(c.unaryClientInterceptor()),
+		grpc.WithStreamInterceptor(c.streamClientInterceptor()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+	return c.conn, nil
+}
+
+// unaryClientInterceptor chains the client interceptors configured at call
+// time, so the shared connection picks up interceptors added after dialing
+func (c *Client) unaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		c.cfg.mu.Lock()
+		interceptors := c.cfg.UnaryClientInterceptors
+		c.cfg.mu.Unlock()
+		return WrapperUnaryClient(interceptors...)(ctx, method, req, reply, cc, invoker, opts...)
+	}
+}
+
+// streamClientInterceptor chains the client stream interceptors configured
+// at call time
+func (c *Client) streamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		c.cfg.mu.Lock()
+		interceptors := c.cfg.StreamClientInterceptors
+		c.cfg.mu.Unlock()
+		return WrapperStreamClient(interceptors...)(ctx, desc, cc, method, streamer, opts...)
+	}
 }
 
 // Dial create a gRPC channel to communicate with the server
+// Note: deprecated, a new connection is established on every call, please
+// use Conn to reuse the client shared connection
 func (c *Client) Dial(opts ...Option) (*grpc.ClientConn, error) {
 	c.applyOptions(opts...)
-	// TODO use TLS
 	c.cfg.mu.Lock()
 	defer c.cfg.mu.Unlock()
 	conn, err := grpc.Dial(
 		c.cfg.Target,
-		grpc.WithInsecure(),
+		c.transportCredentials(),
 		grpc.WithBlock(),
 		grpc.WithTimeout(c.cfg.Timeout),
 		grpc.WithUnaryInterceptor(WrapperUnaryClient(c.cfg.UnaryClientInterceptors...)),
@@ -98,19 +172,18 @@ func (c *Client) Dial(opts ...Option) (*grpc.ClientConn, error) {
 	return conn, nil
 }
 
-// Dial create a gRPC channel to communicate with the server
+// DialWithCredentials create a gRPC channel to communicate with the server
+// Note: deprecated, please use Conn together with the Token call option
 func (c *Client) DialWithCredentials(token string, opts ...Option) (*grpc.ClientConn, error) {
 	c.applyOptions(opts...)
-	// TODO use TLS
 	c.cfg.mu.Lock()
 	defer c.cfg.mu.Unlock()
 	conn, err := grpc.Dial(
 		c.cfg.Target,
-		grpc.WithInsecure(),
+		c.transportCredentials(),
 		grpc.WithPerRPCCredentials(TokenAuth{
 			token: token,
 		}),
-		grpc.WithInsecure(),
 		grpc.WithBlock(),
 		grpc.WithTimeout(c.cfg.Timeout),
 		grpc.WithUnaryInterceptor(WrapperUnaryClient(c.cfg.UnaryClientInterceptors...)),
@@ -125,14 +198,71 @@ func (c *Client) DialWithCredentials(token string, opts ...Option) (*grpc.Client
 	return conn, nil
 }
 
+// transportCredentials returns the dial option securing the connection with
+// TLS, if configured
+func (c *Client) transportCredentials() grpc.DialOption {
+	if c.cfg.TLSConfig == nil {
+		return grpc.WithInsecure()
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(c.cfg.TLSConfig.Clone()))
+}
+
 // Stub to perform RPCs
 func (c *Client) Stub(conn *grpc.ClientConn) interface{} {
 	return c.cfg.GRPCRegister(conn)
 }
 
-// Close not implemented
+// StubOf returns the stub registered with GRPCRegister, built on top of the
+// client shared connection, as its concrete type T
+// e.g. stub, err := client.StubOf[pb.GreeterClient](c)
+func StubOf[T any](c *Client) (T, error) {
+	var stub T
+	if c.cfg.GRPCRegister == nil {
+		return stub, fmt.Errorf("grpc client: %v has no GRPCRegister function", c.Name())
+	}
+	conn, err := c.Conn()
+	if err != nil {
+		return stub, err
+	}
+	v := c.Stub(conn)
+	stub, ok := v.(T)
+	if !ok {
+		return stub, fmt.Errorf("grpc client: %v stub %T does not implement %v", c.Name(), v, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return stub, nil
+}
+
+// Close shuts down the shared connection, if any
 func (c *Client) Close() error {
-	return nil
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unwatch != nil {
+		c.unwatch()
+		c.unwatch = nil
+	}
+	if c.conn == nil {
+		return nil
+	}
+	c.logger.Info().Msg(fmt.Sprintf("closing %s %s", c.cfg.Format, c.Name()))
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// Validate returns any configuration that would prevent the client from
+// performing calls
+func (c *Client) Validate() error {
+	var errs common.Errors
+	for _, err := range c.errs {
+		errs = append(errs, fmt.Errorf("%s: %v", c.Name(), err))
+	}
+	if c.cfg.Target == "" {
+		errs = append(errs, fmt.Errorf("%s: target is required", c.Name()))
+	}
+	if c.cfg.GRPCRegister == nil {
+		errs = append(errs, fmt.Errorf("%s: grpc client requires a GRPCRegister function", c.Name()))
+	}
+	return errs.Err()
 }
 
 // Name returns client name
@@ -141,16 +271,16 @@ func (c *Client) Name() string {
 }
 
 func (c *Client) healthRPC() {
-	conn, err := c.Dial()
+	conn, err := c.Conn()
 	if err != nil {
 		c.logger.Error().Msg(err.Error())
 		c.health.SetServingStatus(c.cfg.ID, healthpb.HealthCheckResponse_NOT_SERVING)
 		return
 	}
-	defer conn.Close()
-
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	defer cancel()
 	h := healthpb.NewHealthClient(conn)
-	hcr, err := h.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	hcr, err := h.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true))
 	if err != nil {
 		c.logger.Error().Msg(err.Error())
 		c.health.SetServingStatus(c.cfg.ID, healthpb.HealthCheckResponse_NOT_SERVING)
@@ -173,9 +303,19 @@ func (c *Client) Health() *healthpb.HealthCheckResponse {
 		c.logger.Error().Msg(err.Error())
 		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}
 	}
+	// servers reject the certificate once expired
+	if c.cfg.Certificates != nil && c.cfg.Certificates.CheckExpiry().Expired {
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}
+	}
 	return hcr
 }
 
+// Token returns a call option that sends token as bearer credentials
+// on a single RPC made through the shared connection
+func Token(token string) grpc.CallOption {
+	return grpc.PerRPCCredentials(TokenAuth{token: token})
+}
+
 // Token based authentication
 type TokenAuth struct {
 	token string
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aukbit/pluto/v6/trace"
+	"github.com/paulormart/assert"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestConn(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := grpc.NewServer()
+	healthpb.RegisterHealthServer(g, health.NewServer())
+	go g.Serve(ln)
+	defer g.Stop()
+
+	c := New(
+		Target(ln.Addr().String()),
+		GRPCRegister(func(cc *grpc.ClientConn) interface{} {
+			return healthpb.NewHealthClient(cc)
+		}),
+	)
+	c.Init()
+	conn1, err := c.Conn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn2, err := c.Conn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// connection is shared between calls
+	assert.Equal(t, conn1, conn2)
+	assert.Equal(t, "SERVING", c.Health().Status.String())
+	// close shuts down the shared connection
+	assert.Equal(t, nil, c.Close())
+	conn3, err := c.Conn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, false, conn1 == conn3)
+	assert.Equal(t, nil, c.Close())
+}
+
+func TestStreamMetrics(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := grpc.NewServer()
+	healthpb.RegisterHealthServer(g, health.NewServer())
+	go g.Serve(ln)
+	defer g.Stop()
+
+	c := New(Name("streams"), Target(ln.Addr().String()))
+	c.Init()
+	defer c.Close()
+	conn, err := c.Conn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	// the call is recorded once the stream is done
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+
+	w := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	for _, metric := range []string{
+		`pluto_grpc_client_handled_total{client="` + c.Name() + `",code="Canceled",method="/grpc.health.v1.Health/Watch"} 1`,
+		`pluto_grpc_client_handling_seconds_count{client="` + c.Name() + `",code="Canceled",method="/grpc.health.v1.Health/Watch"} 1`,
+	} {
+		if !strings.Contains(w.Body.String(), metric) {
+			t.Errorf("%s not exposed", metric)
+		}
+	}
+}
+
+// testDesc describes a unary call echoing the service requested and a client
+// streaming call replying once every request is received
+var testDesc = grpc.ServiceDesc{
+	ServiceName: "pluto.test.Test",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Echo",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := &healthpb.HealthCheckRequest{}
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			return in, nil
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Upload",
+		ClientStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			for {
+				err := stream.RecvMsg(&healthpb.HealthCheckRequest{})
+				if err == io.EOF {
+					return stream.SendMsg(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+				}
+				if err != nil {
+					return err
+				}
+			}
+		},
+	}},
+}
+
+func TestClientStream(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := grpc.NewServer()
+	g.RegisterService(&testDesc, struct{}{})
+	go g.Serve(ln)
+	defer g.Stop()
+
+	exp := trace.NewInMemoryExporter()
+	c := New(Name("uploads"), Target(ln.Addr().String()), Tracer(trace.NewTracer(exp)))
+	c.Init()
+	defer c.Close()
+	conn, err := c.Conn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const method = "/pluto.test.Test/Upload"
+	// the call is done once the response is received, as by CloseAndRecv
+	stream, err := conn.NewStream(context.Background(), &testDesc.Streams[0], method)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := stream.SendMsg(&healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	reply := &healthpb.HealthCheckResponse{}
+	assert.Equal(t, nil, stream.RecvMsg(reply))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, reply.Status)
+	spans := exp.Spans()
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, method, spans[0].Name)
+
+	// abandoned calls are done once their context is
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := conn.NewStream(ctx, &testDesc.Streams[0], method); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	for i := 0; i < 100 && len(exp.Spans()) < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 2, len(exp.Spans()))
+
+	w := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	for _, metric := range []string{
+		`pluto_grpc_client_handled_total{client="` + c.Name() + `",code="OK",method="` + method + `"} 1`,
+		`pluto_grpc_client_handling_seconds_count{client="` + c.Name() + `",code="OK",method="` + method + `"} 1`,
+		`pluto_grpc_client_handling_seconds_count{client="` + c.Name() + `",code="Canceled",method="` + method + `"} 1`,
+	} {
+		if !strings.Contains(w.Body.String(), metric) {
+			t.Errorf("%s not exposed", metric)
+		}
+	}
+}
+
+func TestRedactionNil(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := grpc.NewServer()
+	g.RegisterService(&testDesc, struct{}{})
+	go g.Serve(ln)
+	defer g.Stop()
+
+	// nil keeps the default policy
+	c := New(Target(ln.Addr().String()), Redaction(nil))
+	c.Init()
+	defer c.Close()
+	conn, err := c.Conn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := &healthpb.HealthCheckRequest{}
+	assert.Equal(t, nil, conn.Invoke(context.Background(), "/pluto.test.Test/Echo", &healthpb.HealthCheckRequest{Service: "gopher"}, reply))
+	assert.Equal(t, "gopher", reply.Service)
+}
@@ -1,10 +1,14 @@
 package client
 
 import (
+	"crypto/tls"
 	"sync"
 	"time"
 
+	"github.com/aukbit/pluto/v6/certificate"
 	"github.com/aukbit/pluto/v6/common"
+	"github.com/aukbit/pluto/v6/redact"
+	"github.com/aukbit/pluto/v6/trace"
 
 	"google.golang.org/grpc"
 )
@@ -18,6 +22,10 @@ type Config struct {
 	Format                   string
 	GRPCRegister             func(*grpc.ClientConn) interface{}
 	Timeout                  time.Duration
+	TLSConfig                *tls.Config                    // optional TLS config, calls are made in plaintext if nil
+	Certificates             *certificate.Source            // optional client key pair reloaded on change
+	Tracer                   *trace.Tracer                  // spans are created and propagated even if nil, but not exported
+	Redaction                *redact.Policy                 // payload fields redacted from logs
 	mu                       sync.Mutex                     // ensures atomic writes; protects the following fields
 	UnaryClientInterceptors  []grpc.UnaryClientInterceptor  // gRPC interceptors
 	StreamClientInterceptors []grpc.StreamClientInterceptor // gRPC interceptors
@@ -30,9 +38,10 @@ var (
 
 func newConfig() Config {
 	return Config{
-		ID:      common.RandID("clt_", 6),
-		Name:    DefaultName,
-		Format:  defaultFormat,
-		Timeout: 500 * time.Millisecond,
+		ID:        common.RandID("clt_", 6),
+		Name:      DefaultName,
+		Format:    defaultFormat,
+		Timeout:   500 * time.Millisecond,
+		Redaction: redact.New(),
 	}
 }
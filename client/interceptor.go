@@ -3,10 +3,10 @@ package client
 // loggerUnaryClientInterceptor ...
 import (
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/aukbit/pluto/v6/common"
+	"github.com/aukbit/pluto/v6/trace"
 
 	"golang.org/x/net/context"
 
@@ -27,16 +27,22 @@ func dialUnaryClientInterceptor(clt *Client) grpc.UnaryClientInterceptor {
 		start := time.Now()
 		e := eidFromIncomingContext(ctx)
 		ctx = eidToOutgoingContext(ctx, e)
+		ctx, span := clt.startSpan(ctx, method)
+		defer span.End()
 		// sets new logger instance with eventID
 		sublogger := clt.logger.With().Str("eid", e).Logger()
-		sublogger.Info().
-			Str("method", method).
-			Str("data", fmt.Sprintf("%v", req)).
-			Msgf("call %s", method)
+		sublogger = trace.Logger(ctx, sublogger)
+		l := sublogger.Info().Str("method", method)
+		if data, ok := clt.cfg.Redaction.Payload(method, req); ok {
+			l = l.Str("data", data)
+		}
+		l.Msgf("call %s", method)
 		// also nice to have a logger available in context
 		ctx = sublogger.WithContext(ctx)
 		err := invoker(ctx, method, req, reply, cc, opts...)
 		end := time.Now()
+		observe(clt, method, start, err)
+		endSpan(span, err)
 		sublogger.Info().Msgf("response %s received - duration: %v", method, end.Sub(start))
 		return err
 	}
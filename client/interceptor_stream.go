@@ -3,21 +3,92 @@ package client
 // loggerUnaryClientInterceptor ...
 import (
 	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aukbit/pluto/v6/trace"
 
 	"golang.org/x/net/context"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
 )
 
 func dialStreamClientInterceptor(clt *Client) grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
 		e := eidFromIncomingContext(ctx)
 		ctx = eidToOutgoingContext(ctx, e)
+		ctx, span := clt.startSpan(ctx, method)
 		// sets new logger instance with eventID
 		sublogger := clt.logger.With().Str("eid", e).Logger()
+		sublogger = trace.Logger(ctx, sublogger)
 		sublogger.Info().Str("method", method).Msg(fmt.Sprintf("call %s", method))
 		// also nice to have a logger available in context
 		ctx = sublogger.WithContext(ctx)
-		return streamer(ctx, desc, cc, method, opts...)
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			observe(clt, method, start, err)
+			endSpan(span, err)
+			span.End()
+			return nil, err
+		}
+		s := &tracedClientStream{
+			ClientStream: cs,
+			desc:         desc,
+			span:         span,
+			clt:          clt,
+			method:       method,
+			start:        start,
+			done:         make(chan struct{}),
+		}
+		// streams abandoned by the caller are done once ctx is
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.finish(status.FromContextError(ctx.Err()).Err())
+			case <-s.done:
+			}
+		}()
+		return s, nil
+	}
+}
+
+// tracedClientStream ends the client span and records the call metrics once
+// the stream is done, that is when RecvMsg returns an error or io.EOF, when
+// it returns the response of a client streaming call or when the context of
+// the call is done
+type tracedClientStream struct {
+	grpc.ClientStream
+	desc   *grpc.StreamDesc
+	span   *trace.Span
+	clt    *Client
+	method string
+	start  time.Time
+	once   sync.Once
+	done   chan struct{}
+}
+
+func (s *tracedClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		s.finish(nil)
+	case err != nil:
+		s.finish(err)
+	case !s.desc.ServerStreams:
+		s.finish(nil)
 	}
+	return err
+}
+
+// finish records the outcome err of the stream, once
+func (s *tracedClientStream) finish(err error) {
+	s.once.Do(func() {
+		observe(s.clt, s.method, s.start, err)
+		endSpan(s.span, err)
+		s.span.End()
+		close(s.done)
+	})
 }
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcClientHandledTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pluto_grpc_client_handled_total",
+			Help: "Total number of gRPC calls made, by client, method and status code.",
+		},
+		[]string{"client", "method", "code"},
+	)
+	grpcClientHandlingSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "pluto_grpc_client_handling_seconds",
+			Help:    "Latency of gRPC calls made, by client, method and status code.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"client", "method", "code"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(grpcClientHandledTotal, grpcClientHandlingSeconds)
+}
+
+// observe records a call to method made by clt since start
+func observe(clt *Client, method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	grpcClientHandledTotal.WithLabelValues(clt.Name(), method, code).Inc()
+	grpcClientHandlingSeconds.WithLabelValues(clt.Name(), method, code).Observe(time.Since(start).Seconds())
+}
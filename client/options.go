@@ -1,9 +1,14 @@
 package client
 
 import (
+	"crypto/tls"
+	"fmt"
 	"time"
 
+	"github.com/aukbit/pluto/v6/certificate"
 	"github.com/aukbit/pluto/v6/common"
+	"github.com/aukbit/pluto/v6/redact"
+	"github.com/aukbit/pluto/v6/trace"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
 )
@@ -73,6 +78,25 @@ func StreamClientInterceptors(uci []grpc.StreamClientInterceptor) Option {
 	})
 }
 
+// Tracer sets the tracer exporting the spans of grpc calls
+func Tracer(t *trace.Tracer) Option {
+	return optionFunc(func(c *Client) {
+		c.cfg.Tracer = t
+	})
+}
+
+// Redaction sets what is redacted from call logs. The default policy masks
+// fields annotated as sensitive, with payloads capped to 1024 bytes, which a
+// nil policy leaves in place.
+func Redaction(p *redact.Policy) Option {
+	return optionFunc(func(c *Client) {
+		if p == nil {
+			return
+		}
+		c.cfg.Redaction = p
+	})
+}
+
 // Logger sets a shallow copy from an input logger
 func Logger(l zerolog.Logger) Option {
 	return optionFunc(func(c *Client) {
@@ -87,3 +111,64 @@ func Timeout(d time.Duration) Option {
 		c.cfg.Timeout = d
 	})
 }
+
+// RootCAs secures calls with TLS, verifying the server certificate against
+// the certificate authorities in caFiles, or the system ones if none given
+// Note: if a certificate can not be loaded the error is reported by Validate
+func RootCAs(caFiles ...string) Option {
+	return optionFunc(func(c *Client) {
+		cfg := c.tlsConfig()
+		if len(caFiles) == 0 {
+			return
+		}
+		pool, err := common.CertPool(caFiles...)
+		if err != nil {
+			c.errs = append(c.errs, fmt.Errorf("root CAs: %v", err))
+			return
+		}
+		cfg.RootCAs = pool
+	})
+}
+
+// Certificate secures calls with TLS, presenting the key pair to servers
+// requiring client certificates (mutual TLS)
+// Note: if the key pair can not be loaded the error is reported by Validate
+func Certificate(certFile, keyFile string) Option {
+	return optionFunc(func(c *Client) {
+		cer, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			c.errs = append(c.errs, fmt.Errorf("certificate: %v", err))
+			return
+		}
+		cfg := c.tlsConfig()
+		cfg.Certificates = []tls.Certificate{cer}
+	})
+}
+
+// CertificateSource secures calls with TLS, presenting the key pair of src,
+// reloaded whenever its files change or the process receives SIGHUP, to
+// servers requiring client certificates (mutual TLS)
+func CertificateSource(src *certificate.Source) Option {
+	return optionFunc(func(c *Client) {
+		c.cfg.Certificates = src
+		cfg := c.tlsConfig()
+		cfg.Certificates = nil
+		cfg.GetClientCertificate = src.GetClientCertificate
+	})
+}
+
+// ServerName secures calls with TLS, verifying the server certificate
+// against name instead of the host in Target
+func ServerName(name string) Option {
+	return optionFunc(func(c *Client) {
+		c.tlsConfig().ServerName = name
+	})
+}
+
+// tlsConfig returns the client TLS config, created if calls are not secured yet
+func (c *Client) tlsConfig() *tls.Config {
+	if c.cfg.TLSConfig == nil {
+		c.cfg.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return c.cfg.TLSConfig
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"errors"
+	"os"
+
+	"github.com/aukbit/pluto/v6/common"
+)
+
+// ErrUnknownSetting is returned by Settings.Set for keys that do not match
+// any client setting
+var ErrUnknownSetting = errors.New("unknown client setting")
+
+// Settings declarative client configuration, e.g. loaded from a YAML or
+// JSON file. Zero values are left to the client defaults.
+type Settings struct {
+	Description string          `json:"description" yaml:"description"`
+	Target      string          `json:"target" yaml:"target"`
+	Timeout     common.Duration `json:"timeout" yaml:"timeout"`
+	TLSCAFile   string          `json:"tls_ca_file" yaml:"tls_ca_file"`
+	TLSCertFile string          `json:"tls_cert_file" yaml:"tls_cert_file"`
+	TLSKeyFile  string          `json:"tls_key_file" yaml:"tls_key_file"`
+	TLSServer   string          `json:"tls_server_name" yaml:"tls_server_name"`
+}
+
+// Set assigns the string value to the setting key, where key is the
+// YAML/JSON field name, e.g. "target" or "timeout"
+func (st *Settings) Set(key, value string) error {
+	switch key {
+	case "description":
+		st.Description = value
+	case "target":
+		st.Target = value
+	case "timeout":
+		return st.Timeout.Set(value)
+	case "tls_ca_file":
+		st.TLSCAFile = value
+	case "tls_cert_file":
+		st.TLSCertFile = value
+	case "tls_key_file":
+		st.TLSKeyFile = value
+	case "tls_server_name":
+		st.TLSServer = value
+	default:
+		return ErrUnknownSetting
+	}
+	return nil
+}
+
+// Merge overrides the settings of st with the ones set in o
+func (st *Settings) Merge(o Settings) {
+	for _, f := range []struct {
+		dst *string
+		v   string
+	}{
+		{&st.Description, o.Description},
+		{&st.Target, o.Target},
+		{&st.TLSCAFile, o.TLSCAFile},
+		{&st.TLSCertFile, o.TLSCertFile},
+		{&st.TLSKeyFile, o.TLSKeyFile},
+		{&st.TLSServer, o.TLSServer},
+	} {
+		if f.v != "" {
+			*f.dst = f.v
+		}
+	}
+	if o.Timeout != 0 {
+		st.Timeout = o.Timeout
+	}
+}
+
+// Validate returns an error describing every invalid setting
+func (st *Settings) Validate() []error {
+	var errs []error
+	if st.Timeout < 0 {
+		errs = append(errs, errors.New("timeout must not be negative"))
+	}
+	if (st.TLSCertFile == "") != (st.TLSKeyFile == "") {
+		errs = append(errs, errors.New("tls_cert_file and tls_key_file must be set together"))
+	}
+	for _, f := range []string{st.TLSCAFile, st.TLSCertFile, st.TLSKeyFile} {
+		if f == "" {
+			continue
+		}
+		if _, err := os.Stat(f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Options returns the client options equivalent to the settings
+func (st *Settings) Options(name string) []Option {
+	opts := []Option{Name(name)}
+	if st.Description != "" {
+		opts = append(opts, Description(st.Description))
+	}
+	if st.Target != "" {
+		opts = append(opts, Target(st.Target))
+	}
+	if st.Timeout != 0 {
+		opts = append(opts, Timeout(st.Timeout.Duration()))
+	}
+	if st.TLSCAFile != "" {
+		opts = append(opts, RootCAs(st.TLSCAFile))
+	}
+	if st.TLSCertFile != "" {
+		opts = append(opts, Certificate(st.TLSCertFile, st.TLSKeyFile))
+	}
+	if st.TLSServer != "" {
+		opts = append(opts, ServerName(st.TLSServer))
+	}
+	return opts
+}
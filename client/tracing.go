@@ -0,0 +1,33 @@
+package client
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/aukbit/pluto/v6/trace"
+)
+
+// startSpan starts a client span for the grpc method and propagates it to
+// the server in the outgoing metadata traceparent
+func (c *Client) startSpan(ctx context.Context, method string) (context.Context, *trace.Span) {
+	ctx, span := c.cfg.Tracer.Start(ctx, method, trace.SpanKindClient)
+	span.SetAttribute("rpc.system", "grpc")
+	span.SetAttribute("rpc.method", method)
+	span.SetAttribute("pluto.client", c.Name())
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md = metadata.New(map[string]string{})
+	}
+	md = md.Copy()
+	trace.InjectMetadata(ctx, md)
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// endSpan records the status of the call in span
+func endSpan(span *trace.Span, err error) {
+	span.SetAttribute("rpc.grpc.status_code", status.Code(err).String())
+	if err != nil {
+		span.SetStatus(trace.StatusError, err.Error())
+	}
+}
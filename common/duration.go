@@ -0,0 +1,43 @@
+package common
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Duration is a time.Duration that can be decoded from JSON and YAML
+// as a string such as "300ms" or "10s"
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return d.Set(s)
+}
+
+// UnmarshalYAML parses a duration string
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return d.Set(s)
+}
+
+// Set parses a duration string
+func (d *Duration) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Duration returns d as time.Duration
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
@@ -0,0 +1,24 @@
+package common
+
+import "strings"
+
+// Errors aggregates several errors, e.g. configuration validation errors,
+// so they can be reported at once
+type Errors []error
+
+// Error returns all error messages separated by semicolons
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Err returns nil when there are no errors, otherwise e
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
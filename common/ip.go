@@ -1,6 +1,7 @@
 package common
 
 import (
+	"fmt"
 	"log"
 	"net"
 )
@@ -23,3 +24,17 @@ func IPaddress() string {
 	}
 	return "localhost"
 }
+
+// Port returns the numeric port of a TCP address, e.g. ":8080",
+// "localhost:8080" or ":http"
+func Port(addr string) (int, error) {
+	_, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return 0, err
+	}
+	port, err := net.LookupPort("tcp", p)
+	if err != nil {
+		return 0, fmt.Errorf("address %s: invalid port %q", addr, p)
+	}
+	return port, nil
+}
@@ -0,0 +1,23 @@
+package common
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// CertPool returns a pool with the PEM encoded certificates in files, e.g.
+// the certificate authorities trusted to verify peers
+func CertPool(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificates found in %s", f)
+		}
+	}
+	return pool, nil
+}
@@ -1,12 +1,16 @@
 package pluto
 
 import (
+	"sync"
+	"time"
+
 	context "golang.org/x/net/context"
 
 	"github.com/aukbit/pluto/v6/client"
 	"github.com/aukbit/pluto/v6/common"
 	"github.com/aukbit/pluto/v6/discovery"
 	"github.com/aukbit/pluto/v6/server"
+	"github.com/aukbit/pluto/v6/trace"
 )
 
 // Config pluto service config
@@ -14,25 +18,38 @@ type Config struct {
 	ID          string
 	Name        string
 	Description string
-	clientsCh   chan *client.Client
 	Discovery   discovery.Discovery
 	HealthAddr  string // TCP address (e.g. localhost:8000) to listen on, ":http" if empty
 	Servers     map[string]*server.Server
 	Clients     map[string]*client.Client
 	Hooks       map[string][]HookFunc
+	Tracer      *trace.Tracer
+	mu          sync.RWMutex // protects Servers, Clients and running
+	running     bool         // servers and clients pushed are started
+	// ShutdownTimeout is the maximum duration for stop hooks, unregistration,
+	// servers draining and clients closing. When exceeded the process exits.
+	ShutdownTimeout time.Duration
 }
 
 // HookFunc hook function type
 type HookFunc func(context.Context) error
 
+// lifecycle hook names in the order they run
+const (
+	hookBeforeStart = "before_start"
+	hookAfterStart  = "after_start"
+	hookBeforeStop  = "before_stop"
+	hookAfterStop   = "after_stop"
+)
+
 func newConfig() Config {
 	return Config{
-		ID:         common.RandID("plt_", 6),
-		Name:       defaultName,
-		Servers:    make(map[string]*server.Server),
-		Clients:    make(map[string]*client.Client),
-		clientsCh:  make(chan *client.Client, 100),
-		Hooks:      make(map[string][]HookFunc),
-		HealthAddr: defaultHealthAddr,
+		ID:              common.RandID("plt_", 6),
+		Name:            defaultName,
+		Servers:         make(map[string]*server.Server),
+		Clients:         make(map[string]*client.Client),
+		Hooks:           make(map[string][]HookFunc),
+		HealthAddr:      defaultHealthAddr,
+		ShutdownTimeout: defaultShutdownTimeout,
 	}
 }
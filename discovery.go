@@ -2,6 +2,8 @@ package pluto
 
 import (
 	"fmt"
+	"net"
+	"strconv"
 
 	"github.com/aukbit/pluto/v6/common"
 	"github.com/aukbit/pluto/v6/discovery"
@@ -10,20 +12,25 @@ import (
 // register Pluto Service within the service discovery system
 func (s *Service) register() error {
 	if _, ok := s.cfg.Discovery.(discovery.Discovery); ok {
+		port, _ := common.Port(s.cfg.HealthAddr)
 		// define service
 		dse := discovery.Service{
-			Name: s.cfg.Name,
-			Tags: []string{s.cfg.ID},
+			ID:      s.cfg.ID,
+			Name:    s.cfg.Name,
+			Address: common.IPaddress(),
+			Port:    port,
+			Tags:    []string{s.cfg.ID},
 		}
 		// define check
 		dck := discovery.Check{
-			Name:  fmt.Sprintf("Service '%s' check", s.cfg.Name),
-			Notes: fmt.Sprintf("Ensure the Pluto service %s is running", s.cfg.ID),
+			ID:                             "check_" + s.cfg.ID,
+			Name:                           fmt.Sprintf("Service '%s' check", s.cfg.Name),
+			Notes:                          fmt.Sprintf("Ensure the Pluto service %s is running", s.cfg.ID),
 			DeregisterCriticalServiceAfter: "10m",
-			HTTP:      fmt.Sprintf("http://%s:9090/_health/pluto/%s", common.IPaddress(), s.cfg.Name),
-			Interval:  "10s",
-			Timeout:   "1s",
-			ServiceID: s.cfg.Name,
+			HTTP:                           s.healthURL("pluto", s.cfg.Name),
+			Interval:                       "10s",
+			Timeout:                        "1s",
+			ServiceID:                      s.cfg.ID,
 		}
 		if err := s.cfg.Discovery.Register(discovery.ServicesCfg(dse), discovery.ChecksCfg(dck)); err != nil {
 			return err
@@ -32,12 +39,24 @@ func (s *Service) register() error {
 	return nil
 }
 
-// unregister Server from the service discovery system
+// unregister Pluto Service from the service discovery system, servers
+// unregister themselves when they stop
 func (s *Service) unregister() error {
 	if _, ok := s.cfg.Discovery.(discovery.Discovery); ok {
-		if err := s.cfg.Discovery.Unregister(); err != nil {
+		if err := s.cfg.Discovery.Deregister(s.cfg.ID); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// healthURL returns the url of the health handler for the named module
+// (pluto, server or client) served by the service health server
+func (s *Service) healthURL(module, name string) string {
+	host, _, _ := net.SplitHostPort(s.cfg.HealthAddr)
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = common.IPaddress()
+	}
+	port, _ := common.Port(s.cfg.HealthAddr)
+	return fmt.Sprintf("http://%s/_health/%s/%s", net.JoinHostPort(host, strconv.Itoa(port)), module, name)
+}
@@ -1,6 +1,9 @@
 package discovery
 
+import "sync"
+
 type consulDefault struct {
+	mu  sync.Mutex // protects cfg services and checks
 	cfg *Config
 }
 
@@ -21,6 +24,8 @@ func (cd *consulDefault) Service(serviceID string) ([]string, error) {
 }
 
 func (cd *consulDefault) Register(cfgs ...ConfigFunc) error {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
 	// set last configs
 	for _, c := range cfgs {
 		c(cd.cfg)
@@ -42,7 +47,31 @@ func (cd *consulDefault) Register(cfgs ...ConfigFunc) error {
 	return nil
 }
 
+// Deregister removes the service registered with serviceID and its checks
+func (cd *consulDefault) Deregister(serviceID string) error {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	// unregister checks
+	for id, c := range cd.cfg.Checks {
+		if c.ServiceID != serviceID {
+			continue
+		}
+		if err := DoCheckUnregister(&DefaultCheckRegister{}, cd.cfg.Addr, c.ID); err != nil {
+			return err
+		}
+		delete(cd.cfg.Checks, id)
+	}
+	// unregister service
+	if err := DoServiceUnregister(&DefaultServiceRegister{}, cd.cfg.Addr, serviceID); err != nil {
+		return err
+	}
+	delete(cd.cfg.Services, serviceID)
+	return nil
+}
+
 func (cd *consulDefault) Unregister() error {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
 	// unregister services
 	for _, s := range cd.cfg.Services {
 		if err := DoServiceUnregister(&DefaultServiceRegister{}, cd.cfg.Addr, s.ID); err != nil {
@@ -5,6 +5,8 @@ type Discovery interface {
 	IsAvailable() (bool, error)
 	Service(string) ([]string, error)
 	Register(...ConfigFunc) error
+	// Deregister removes the service registered with serviceID and its checks
+	Deregister(serviceID string) error
 	Unregister() error
 }
 
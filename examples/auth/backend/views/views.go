@@ -2,12 +2,10 @@ package backend
 
 import (
 	"errors"
-	"time"
 
 	"github.com/aukbit/pluto/v6"
 	"github.com/aukbit/pluto/v6/auth/jwt"
 	pba "github.com/aukbit/pluto/v6/auth/proto"
-	"github.com/aukbit/pluto/v6/client"
 	pbu "github.com/aukbit/pluto/v6/examples/user/proto"
 	"golang.org/x/net/context"
 )
@@ -27,12 +25,11 @@ func (av *AuthViews) Authenticate(ctx context.Context, cre *pba.Credentials) (*p
 	if !ok {
 		return &pba.Token{}, errClientUserNotAvailable
 	}
-	// dial
-	conn, err := c.Dial(client.Timeout(5 * time.Second))
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		return &pba.Token{}, err
 	}
-	defer conn.Close()
 	// make a call to user backend service for credentials verification
 	nCred := &pbu.Credentials{Email: cre.Email, Password: cre.Password}
 	v, err := c.Stub(conn).(pbu.UserServiceClient).VerifyUser(ctx, nCred)
@@ -3,11 +3,9 @@ package frontend
 import (
 	"errors"
 	"net/http"
-	"time"
 
 	"github.com/aukbit/pluto/v6"
 	pba "github.com/aukbit/pluto/v6/auth/proto"
-	"github.com/aukbit/pluto/v6/client"
 	"github.com/aukbit/pluto/v6/reply"
 )
 
@@ -33,13 +31,12 @@ func PostHandler(w http.ResponseWriter, r *http.Request) {
 		reply.Json(w, r, http.StatusInternalServerError, errClientAuthNotAvailable)
 		return
 	}
-	// dial
-	conn, err := c.Dial(client.Timeout(5 * time.Second))
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		reply.Json(w, r, http.StatusInternalServerError, err)
 		return
 	}
-	defer conn.Close()
 	// make a call to the backend service
 	token, err := c.Stub(conn).(pba.AuthServiceClient).Authenticate(ctx, cred)
 	if err != nil {
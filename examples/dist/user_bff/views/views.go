@@ -3,10 +3,8 @@ package views
 import (
 	"errors"
 	"net/http"
-	"time"
 
 	"github.com/aukbit/pluto/v6"
-	"github.com/aukbit/pluto/v6/client"
 	pb "github.com/aukbit/pluto/v6/examples/dist/user_bff/proto"
 	"github.com/aukbit/pluto/v6/reply"
 	"github.com/aukbit/pluto/v6/server/router"
@@ -32,13 +30,12 @@ func PostHandler(w http.ResponseWriter, r *http.Request) {
 		reply.Json(w, r, http.StatusInternalServerError, errClientUserNotAvailable)
 		return
 	}
-	// dial
-	conn, err := c.Dial(client.Timeout(5 * time.Second))
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		reply.Json(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer conn.Close()
 	// make a call the backend service
 	user, err := c.Stub(conn).(pb.UserServiceClient).CreateUser(ctx, newUser)
 	if err != nil {
@@ -61,13 +58,12 @@ func GetHandlerDetail(w http.ResponseWriter, r *http.Request) {
 		reply.Json(w, r, http.StatusInternalServerError, errClientUserNotAvailable)
 		return
 	}
-	// dial
-	conn, err := c.Dial(client.Timeout(5 * time.Second))
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		reply.Json(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer conn.Close()
 	// make a call the backend service
 	user, err = c.Stub(conn).(pb.UserServiceClient).ReadUser(ctx, user)
 	if err != nil {
@@ -95,13 +91,12 @@ func PutHandler(w http.ResponseWriter, r *http.Request) {
 		reply.Json(w, r, http.StatusInternalServerError, errClientUserNotAvailable.Error())
 		return
 	}
-	// dial
-	conn, err := c.Dial(client.Timeout(5 * time.Second))
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		reply.Json(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer conn.Close()
 	// make a call the backend service
 	user, err = c.Stub(conn).(pb.UserServiceClient).UpdateUser(ctx, user)
 	if err != nil {
@@ -124,13 +119,12 @@ func DeleteHandler(w http.ResponseWriter, r *http.Request) {
 		reply.Json(w, r, http.StatusInternalServerError, errClientUserNotAvailable)
 		return
 	}
-	// dial
-	conn, err := c.Dial(client.Timeout(5 * time.Second))
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		reply.Json(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer conn.Close()
 	// make a call the backend service
 	user, err = c.Stub(conn).(pb.UserServiceClient).DeleteUser(ctx, user)
 	if err != nil {
@@ -153,13 +147,12 @@ func GetHandler(w http.ResponseWriter, r *http.Request) {
 		reply.Json(w, r, http.StatusInternalServerError, errClientUserNotAvailable.Error())
 		return
 	}
-	// dial
-	conn, err := c.Dial(client.Timeout(5 * time.Second))
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		reply.Json(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer conn.Close()
 	// make a call the backend service
 	users, err := c.Stub(conn).(pb.UserServiceClient).FilterUsers(ctx, filter)
 	if err != nil {
@@ -6,10 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
 
 	"github.com/aukbit/pluto/v6"
-	"github.com/aukbit/pluto/v6/client"
 	pb "github.com/aukbit/pluto/v6/examples/user/proto"
 	"github.com/aukbit/pluto/v6/reply"
 	"github.com/aukbit/pluto/v6/server/router"
@@ -45,8 +43,8 @@ func PostHandler(w http.ResponseWriter, r *http.Request) *router.Err {
 			Status:  http.StatusInternalServerError,
 		}
 	}
-	// dial
-	conn, err := c.Dial(client.Timeout(2 * time.Second))
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		return &router.Err{
 			Err:     err,
@@ -54,7 +52,6 @@ func PostHandler(w http.ResponseWriter, r *http.Request) *router.Err {
 			Status:  http.StatusInternalServerError,
 		}
 	}
-	defer conn.Close()
 	// make call
 	user, err := c.Stub(conn).(pb.UserServiceClient).CreateUser(ctx, nu)
 	if err != nil {
@@ -96,8 +93,8 @@ func GetHandlerDetail(w http.ResponseWriter, r *http.Request) *router.Err {
 			Status:  http.StatusInternalServerError,
 		}
 	}
-	// dial
-	conn, err := c.Dial()
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		return &router.Err{
 			Err:     err,
@@ -105,7 +102,6 @@ func GetHandlerDetail(w http.ResponseWriter, r *http.Request) *router.Err {
 			Status:  http.StatusInternalServerError,
 		}
 	}
-	defer conn.Close()
 	// make a call the backend service
 	user, err = c.Stub(conn).(pb.UserServiceClient).ReadUser(ctx, user)
 	if err != nil {
@@ -152,15 +148,14 @@ func PutHandler(w http.ResponseWriter, r *http.Request) *router.Err {
 			Status: http.StatusInternalServerError,
 		}
 	}
-	// dial
-	conn, err := c.Dial()
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		return &router.Err{
 			Err:    err,
 			Status: http.StatusInternalServerError,
 		}
 	}
-	defer conn.Close()
 	// make a call the backend service
 	user, err = c.Stub(conn).(pb.UserServiceClient).UpdateUser(ctx, user)
 	if err != nil {
@@ -201,8 +196,8 @@ func DeleteHandler(w http.ResponseWriter, r *http.Request) *router.Err {
 			Status:  http.StatusInternalServerError,
 		}
 	}
-	// dial
-	conn, err := c.Dial()
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		return &router.Err{
 			Err:     err,
@@ -210,7 +205,6 @@ func DeleteHandler(w http.ResponseWriter, r *http.Request) *router.Err {
 			Status:  http.StatusInternalServerError,
 		}
 	}
-	defer conn.Close()
 	// make a call the backend service
 	user, err = c.Stub(conn).(pb.UserServiceClient).DeleteUser(ctx, user)
 	if err != nil {
@@ -242,8 +236,8 @@ func GetHandler(w http.ResponseWriter, r *http.Request) *router.Err {
 			Status:  http.StatusInternalServerError,
 		}
 	}
-	// dial
-	conn, err := c.Dial()
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		return &router.Err{
 			Err:     err,
@@ -251,7 +245,6 @@ func GetHandler(w http.ResponseWriter, r *http.Request) *router.Err {
 			Status:  http.StatusInternalServerError,
 		}
 	}
-	defer conn.Close()
 	// make a call the backend service
 	users, err := c.Stub(conn).(pb.UserServiceClient).FilterUsers(ctx, filter)
 	if err != nil {
@@ -283,8 +276,8 @@ func GetStreamHandler(w http.ResponseWriter, r *http.Request) *router.Err {
 			Status:  http.StatusInternalServerError,
 		}
 	}
-	// dial
-	conn, err := c.Dial()
+	// get shared connection
+	conn, err := c.Conn()
 	if err != nil {
 		return &router.Err{
 			Err:     err,
@@ -292,7 +285,6 @@ func GetStreamHandler(w http.ResponseWriter, r *http.Request) *router.Err {
 			Status:  http.StatusInternalServerError,
 		}
 	}
-	defer conn.Close()
 	// make call
 	stream, err := c.Stub(conn).(pb.UserServiceClient).StreamUsers(ctx, filter)
 	if err != nil {
@@ -15,12 +15,13 @@ func readyHealthHandler(w http.ResponseWriter, r *http.Request) {
 	var hcr = &healthpb.HealthCheckResponse{Status: 0}
 	ctx := r.Context()
 	s := FromContext(ctx)
-	if len(s.cfg.Servers) == 0 {
+	servers := s.servers()
+	if len(servers) == 0 {
 		reply.Json(w, r, http.StatusServiceUnavailable, hcr)
 		return
 	}
 	// Test all servers
-	for _, srv := range s.cfg.Servers {
+	for _, srv := range servers {
 		hcr = srv.Health()
 		if hcr.Status.String() != healthpb.HealthCheckResponse_SERVING.String() {
 			reply.Json(w, r, http.StatusServiceUnavailable, hcr)
@@ -35,7 +36,7 @@ func liveHealthHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	s := FromContext(ctx)
 	// Test all servers
-	for _, srv := range s.cfg.Servers {
+	for _, srv := range s.servers() {
 		hcr = srv.Health()
 		if hcr.Status.String() != healthpb.HealthCheckResponse_SERVING.String() {
 			reply.Json(w, r, http.StatusServiceUnavailable, hcr)
@@ -43,7 +44,7 @@ func liveHealthHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	// Test all clients
-	for _, clt := range s.cfg.Clients {
+	for _, clt := range s.clients() {
 		hcr = clt.Health()
 		if hcr.Status.String() != healthpb.HealthCheckResponse_SERVING.String() {
 			reply.Json(w, r, http.StatusServiceUnavailable, hcr)
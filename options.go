@@ -1,10 +1,14 @@
 package pluto
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/aukbit/pluto/v6/client"
 	"github.com/aukbit/pluto/v6/common"
 	"github.com/aukbit/pluto/v6/discovery"
 	"github.com/aukbit/pluto/v6/server"
+	"github.com/aukbit/pluto/v6/trace"
 	"github.com/rs/zerolog"
 )
 
@@ -44,6 +48,10 @@ func Description(d string) Option {
 // Servers slice of service servers
 func Servers(srv *server.Server) Option {
 	return optionFunc(func(s *Service) {
+		if _, ok := s.cfg.Servers[srv.Name()]; ok {
+			s.errs = append(s.errs, fmt.Errorf("duplicate server name %s", srv.Name()))
+			return
+		}
 		s.cfg.Servers[srv.Name()] = srv
 	})
 }
@@ -51,8 +59,11 @@ func Servers(srv *server.Server) Option {
 // Clients slice of service clients
 func Clients(clt *client.Client) Option {
 	return optionFunc(func(s *Service) {
+		if _, ok := s.cfg.Clients[clt.Name()]; ok {
+			s.errs = append(s.errs, fmt.Errorf("duplicate client name %s", clt.Name()))
+			return
+		}
 		s.cfg.Clients[clt.Name()] = clt
-		s.cfg.clientsCh <- clt
 	})
 }
 
@@ -63,10 +74,51 @@ func Discovery(d discovery.Discovery) Option {
 	})
 }
 
+// HookBeforeStart execute functions before service starts servers and
+// clients, an error returned by any of them aborts Run
+func HookBeforeStart(fn ...HookFunc) Option {
+	return optionFunc(func(s *Service) {
+		s.cfg.Hooks[hookBeforeStart] = append(s.cfg.Hooks[hookBeforeStart], fn...)
+	})
+}
+
 // HookAfterStart execute functions after service starts
 func HookAfterStart(fn ...HookFunc) Option {
 	return optionFunc(func(s *Service) {
-		s.cfg.Hooks["after_start"] = append(s.cfg.Hooks["after_start"], fn...)
+		s.cfg.Hooks[hookAfterStart] = append(s.cfg.Hooks[hookAfterStart], fn...)
+	})
+}
+
+// HookBeforeStop execute functions when service is asked to stop, before
+// it unregisters from discovery and servers stop accepting requests
+func HookBeforeStop(fn ...HookFunc) Option {
+	return optionFunc(func(s *Service) {
+		s.cfg.Hooks[hookBeforeStop] = append(s.cfg.Hooks[hookBeforeStop], fn...)
+	})
+}
+
+// HookAfterStop execute functions after servers are drained and clients
+// closed, e.g. to flush queues or close database pools
+func HookAfterStop(fn ...HookFunc) Option {
+	return optionFunc(func(s *Service) {
+		s.cfg.Hooks[hookAfterStop] = append(s.cfg.Hooks[hookAfterStop], fn...)
+	})
+}
+
+// ShutdownTimeout sets the overall deadline for the service to stop,
+// the process is forced to exit if stopping takes longer. It must be
+// positive, as reported by Validate.
+func ShutdownTimeout(d time.Duration) Option {
+	return optionFunc(func(s *Service) {
+		s.cfg.ShutdownTimeout = d
+	})
+}
+
+// Tracer sets the tracer used by every server and client of the service,
+// flushed when the service stops
+func Tracer(t *trace.Tracer) Option {
+	return optionFunc(func(s *Service) {
+		s.cfg.Tracer = t
 	})
 }
 
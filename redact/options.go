@@ -0,0 +1,54 @@
+package redact
+
+import "net/http"
+
+// Option is used to set options for the policy.
+type Option interface {
+	apply(*Policy)
+}
+
+// optionFunc wraps a func so it satisfies the Option interface.
+type optionFunc func(*Policy)
+
+func (f optionFunc) apply(p *Policy) {
+	f(p)
+}
+
+// Headers adds headers to redact, besides Authorization,
+// Proxy-Authorization, Cookie and Set-Cookie, e.g. X-Api-Key
+func Headers(names ...string) Option {
+	return optionFunc(func(p *Policy) {
+		for _, n := range names {
+			p.headers[http.CanonicalHeaderKey(n)] = true
+		}
+	})
+}
+
+// Fields adds fields to mask, by full name of the message and field name,
+// e.g. auth.Credentials.password, besides the fields annotated as sensitive
+func Fields(paths ...string) Option {
+	return optionFunc(func(p *Policy) {
+		for _, f := range paths {
+			p.fields[f] = true
+		}
+	})
+}
+
+// MaxPayload sets the maximum bytes of a payload logged, the rest is
+// truncated. Payloads are not capped if n is not positive.
+// The default is 1024 bytes.
+func MaxPayload(n int) Option {
+	return optionFunc(func(p *Policy) {
+		p.maxPayload = n
+	})
+}
+
+// SkipPayload turns off payload logging for the grpc methods, by full name
+// e.g. /auth.AuthService/Authenticate
+func SkipPayload(methods ...string) Option {
+	return optionFunc(func(p *Policy) {
+		for _, m := range methods {
+			p.skip[m] = true
+		}
+	})
+}
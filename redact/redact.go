@@ -0,0 +1,236 @@
+// Package redact keeps secrets out of logs, masking sensitive headers and
+// message fields and capping the size of payloads logged.
+//
+// Fields are masked when annotated in the proto definition with
+//
+//	import "redact/proto/redact.proto";
+//
+//	message Credentials {
+//	  string password = 2 [(pluto.redact.sensitive) = true];
+//	}
+//
+// or configured with Fields, e.g. Fields("auth.Credentials.password").
+package redact
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/descriptor"
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/aukbit/pluto/v6/redact/proto"
+)
+
+// Mask replaces the values redacted
+const Mask = "[REDACTED]"
+
+var (
+	defaultHeaders = []string{
+		"Authorization",
+		"Proxy-Authorization",
+		"Cookie",
+		"Set-Cookie",
+	}
+	defaultMaxPayload = 1024
+)
+
+// Policy decides what is redacted from logs
+type Policy struct {
+	headers    map[string]bool // canonical header names redacted
+	fields     map[string]bool // full field names masked, e.g. auth.Credentials.password
+	maxPayload int             // bytes of payload logged, unlimited if not positive
+	skip       map[string]bool // grpc full methods with payloads not logged
+	sensitive  sync.Map        // reflect.Type of messages -> map[string]bool of fields annotated
+}
+
+// New returns a policy redacting the Authorization, Proxy-Authorization,
+// Cookie and Set-Cookie headers and the fields annotated as sensitive, with
+// payloads capped to 1024 bytes, unless configured otherwise with opts
+func New(opts ...Option) *Policy {
+	p := &Policy{
+		headers:    make(map[string]bool),
+		fields:     make(map[string]bool),
+		maxPayload: defaultMaxPayload,
+		skip:       make(map[string]bool),
+	}
+	for _, h := range defaultHeaders {
+		p.headers[h] = true
+	}
+	for _, opt := range opts {
+		opt.apply(p)
+	}
+	return p
+}
+
+// Header returns the values of the header name as they should be logged
+func (p *Policy) Header(name string, values []string) []string {
+	if !p.headers[http.CanonicalHeaderKey(name)] {
+		return values
+	}
+	return []string{Mask}
+}
+
+// Payload returns msg, sent or received by the grpc method, as it should be
+// logged, with sensitive fields masked and capped in size. It reports false
+// if payloads of method are not to be logged.
+func (p *Policy) Payload(method string, msg interface{}) (string, bool) {
+	if p.skip[method] {
+		return "", false
+	}
+	if m, ok := msg.(proto.Message); ok && !isNil(m) {
+		msg = p.Message(m)
+	}
+	s := fmt.Sprintf("%v", msg)
+	if p.maxPayload > 0 && len(s) > p.maxPayload {
+		s = fmt.Sprintf("%s... (%d bytes truncated)", s[:p.maxPayload], len(s)-p.maxPayload)
+	}
+	return s, true
+}
+
+// Message returns a copy of m with sensitive fields masked, strings are set
+// to Mask and other types to their zero value
+func (p *Policy) Message(m proto.Message) proto.Message {
+	c := proto.Clone(m)
+	p.mask(reflect.ValueOf(c))
+	return c
+}
+
+// mask masks the sensitive fields of the message v points to, and of the
+// messages it holds
+func (p *Policy) mask(v reflect.Value) {
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	m, ok := v.Interface().(proto.Message)
+	if !ok {
+		return
+	}
+	sensitive := p.sensitiveFields(m)
+	s := v.Elem()
+	for i := 0; i < s.NumField(); i++ {
+		f, sf := s.Field(i), s.Type().Field(i)
+		if strings.HasPrefix(sf.Name, "XXX_") {
+			continue
+		}
+		if _, ok := sf.Tag.Lookup("protobuf_oneof"); ok {
+			// oneof wrappers hold a single field of the message
+			if f.IsNil() {
+				continue
+			}
+			w := f.Elem().Elem()
+			if sensitive[fieldName(w.Type().Field(0))] {
+				maskValue(w.Field(0))
+				continue
+			}
+			p.maskAll(w.Field(0))
+			continue
+		}
+		if sensitive[fieldName(sf)] {
+			maskValue(f)
+			continue
+		}
+		p.maskAll(f)
+	}
+}
+
+// maskAll masks the messages held by v, itself a message, a list or a map
+func (p *Policy) maskAll(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		p.mask(v)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Ptr {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			p.mask(v.Index(i))
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.Ptr {
+			return
+		}
+		for _, k := range v.MapKeys() {
+			p.mask(v.MapIndex(k))
+		}
+	}
+}
+
+// sensitiveFields returns the names of the fields of m to mask, annotated
+// as sensitive or configured
+func (p *Policy) sensitiveFields(m proto.Message) map[string]bool {
+	annotated := annotatedFields(m, &p.sensitive)
+	if len(p.fields) == 0 {
+		return annotated
+	}
+	prefix := proto.MessageName(m) + "."
+	fields := make(map[string]bool, len(annotated))
+	for k := range annotated {
+		fields[k] = true
+	}
+	for k := range p.fields {
+		if strings.HasPrefix(k, prefix) && !strings.Contains(k[len(prefix):], ".") {
+			fields[k[len(prefix):]] = true
+		}
+	}
+	return fields
+}
+
+// annotatedFields returns the names of the fields of m annotated with
+// (pluto.redact.sensitive) = true, cached by message type
+func annotatedFields(m proto.Message, cache *sync.Map) map[string]bool {
+	t := reflect.TypeOf(m)
+	if v, ok := cache.Load(t); ok {
+		return v.(map[string]bool)
+	}
+	fields := make(map[string]bool)
+	if dm, ok := m.(descriptor.Message); ok {
+		_, md := descriptor.ForMessage(dm)
+		for _, f := range md.GetField() {
+			if f.GetOptions() == nil {
+				continue
+			}
+			ext, err := proto.GetExtension(f.GetOptions(), pb.E_Sensitive)
+			if err != nil {
+				continue
+			}
+			if b, ok := ext.(*bool); ok && *b {
+				fields[f.GetName()] = true
+			}
+		}
+	}
+	cache.Store(t, fields)
+	return fields
+}
+
+// fieldName returns the proto name of the struct field of a message
+func fieldName(sf reflect.StructField) string {
+	for _, s := range strings.Split(sf.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(s, "name=") {
+			return s[len("name="):]
+		}
+	}
+	return ""
+}
+
+// maskValue sets v to Mask if a string, or to its zero value
+func maskValue(v reflect.Value) {
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(Mask)
+	case v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.String && !v.IsNil():
+		// proto2 optional strings
+		v.Set(reflect.ValueOf(proto.String(Mask)))
+	default:
+		v.Set(reflect.Zero(v.Type()))
+	}
+}
+
+// isNil reports whether m is a nil pointer
+func isNil(m proto.Message) bool {
+	v := reflect.ValueOf(m)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
@@ -0,0 +1,69 @@
+package redact_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/paulormart/assert"
+
+	pba "github.com/aukbit/pluto/v6/auth/proto"
+	"github.com/aukbit/pluto/v6/redact"
+	pb "github.com/aukbit/pluto/v6/test/proto"
+)
+
+func TestHeader(t *testing.T) {
+	p := redact.New(redact.Headers("x-api-key"))
+	assert.Equal(t, []string{redact.Mask}, p.Header("Authorization", []string{"Bearer abc"}))
+	assert.Equal(t, []string{redact.Mask}, p.Header("cookie", []string{"session=abc"}))
+	assert.Equal(t, []string{redact.Mask}, p.Header("X-Api-Key", []string{"abc"}))
+	assert.Equal(t, []string{"application/json"}, p.Header("Content-Type", []string{"application/json"}))
+}
+
+func TestAnnotatedFields(t *testing.T) {
+	p := redact.New()
+	in := &pba.Credentials{Email: "gopher@pluto.com", Password: "secret"}
+	data, ok := p.Payload("/auth.AuthService/Authenticate", in)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, false, strings.Contains(data, "secret"))
+	assert.Equal(t, true, strings.Contains(data, redact.Mask))
+	assert.Equal(t, true, strings.Contains(data, "gopher@pluto.com"))
+	// the message itself is left untouched
+	assert.Equal(t, "secret", in.Password)
+}
+
+func TestConfiguredFields(t *testing.T) {
+	p := redact.New(
+		redact.Fields("helloworld.HelloRequest.name", "google.protobuf.FieldDescriptorProto.json_name"),
+	)
+	data, _ := p.Payload("/helloworld.Greeter/SayHello", &pb.HelloRequest{Name: "Gopher"})
+	assert.Equal(t, false, strings.Contains(data, "Gopher"))
+	// nested messages
+	m := p.Message(&descriptor.DescriptorProto{
+		Name: proto.String("Credentials"),
+		Field: []*descriptor.FieldDescriptorProto{
+			{Name: proto.String("email"), JsonName: proto.String("email")},
+			{Name: proto.String("password"), JsonName: proto.String("password")},
+		},
+	}).(*descriptor.DescriptorProto)
+	assert.Equal(t, "Credentials", m.GetName())
+	assert.Equal(t, "email", m.Field[0].GetName())
+	assert.Equal(t, redact.Mask, m.Field[0].GetJsonName())
+	assert.Equal(t, redact.Mask, m.Field[1].GetJsonName())
+}
+
+func TestPayloadSize(t *testing.T) {
+	p := redact.New(redact.MaxPayload(10), redact.SkipPayload("/helloworld.Greeter/SayGoodbye"))
+	data, ok := p.Payload("/helloworld.Greeter/SayHello", &pb.HelloRequest{Name: strings.Repeat("a", 100)})
+	assert.Equal(t, true, ok)
+	assert.Equal(t, true, strings.HasPrefix(data, `name:"aaaa... (`))
+	assert.Equal(t, true, strings.HasSuffix(data, " bytes truncated)"))
+	_, ok = p.Payload("/helloworld.Greeter/SayGoodbye", &pb.GoodbyeRequest{Name: "Gopher"})
+	assert.Equal(t, false, ok)
+	// nil messages
+	var reply *pb.HelloReply
+	data, ok = p.Payload("/helloworld.Greeter/SayHello", reply)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "<nil>", data)
+}
@@ -0,0 +1,154 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/paulormart/assert"
+	"github.com/rs/zerolog"
+
+	"github.com/aukbit/pluto/v6/server"
+	"github.com/aukbit/pluto/v6/server/router"
+)
+
+// syncBuffer is a bytes.Buffer safe to write by the server while read by
+// the test
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Split(strings.TrimSpace(b.buf.String()), "\n")
+}
+
+func accessLogMux() *router.Router {
+	mux := router.New()
+	mux.GET("/rooms/:id", func(w http.ResponseWriter, r *http.Request) {
+		_, flusher := w.(http.Flusher)
+		_, hijacker := w.(http.Hijacker)
+		fmt.Fprintf(w, "flusher=%v hijacker=%v", flusher, hijacker)
+	})
+	mux.GET("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	return mux
+}
+
+func TestAccessLogJSON(t *testing.T) {
+	out := &syncBuffer{}
+	s := server.New(
+		server.Addr(":8100"),
+		server.Mux(accessLogMux()),
+		server.Logger(zerolog.New(out)),
+		// nil keeps the default policy
+		server.Redaction(nil),
+	)
+	go s.Run()
+	defer s.Stop()
+	time.Sleep(time.Millisecond * 100)
+
+	req, _ := http.NewRequest("GET", "http://localhost:8100/rooms/123", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body bytes.Buffer
+	body.ReadFrom(r.Body)
+	r.Body.Close()
+	assert.Equal(t, "flusher=true hijacker=true", body.String())
+	time.Sleep(time.Millisecond * 50)
+
+	var res struct {
+		Method   string  `json:"method"`
+		Route    string  `json:"route"`
+		Status   int     `json:"status"`
+		Size     int     `json:"size"`
+		Duration float64 `json:"duration"`
+	}
+	var found bool
+	for _, l := range out.Lines() {
+		// credentials are redacted from the request headers logged
+		assert.Equal(t, false, strings.Contains(l, "secret-token"))
+		if !strings.Contains(l, `"route"`) {
+			continue
+		}
+		if err := json.Unmarshal([]byte(l), &res); err != nil {
+			t.Fatal(err)
+		}
+		found = true
+	}
+	assert.Equal(t, true, found)
+	assert.Equal(t, "GET", res.Method)
+	assert.Equal(t, "/rooms/:id", res.Route)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, body.Len(), res.Size)
+}
+
+func TestAccessLogCombined(t *testing.T) {
+	out := &syncBuffer{}
+	s := server.New(
+		server.Addr(":8101"),
+		server.Mux(accessLogMux()),
+		server.AccessLogFormat(server.AccessLogCombined),
+		server.AccessLogOutput(out),
+		server.AccessLogSampling("/rooms/:id", 3),
+		server.AccessLogSampling("/fail", 100),
+	)
+	go s.Run()
+	defer s.Stop()
+	time.Sleep(time.Millisecond * 100)
+
+	for i := 0; i < 6; i++ {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("http://localhost:8101/rooms/%d", i), nil)
+		req.SetBasicAuth("gopher", "secret")
+		req.Header.Set("User-Agent", "pluto-test")
+		r, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Body.Close()
+	}
+	// server errors are always logged
+	for i := 0; i < 2; i++ {
+		r, err := http.Get("http://localhost:8101/fail")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Body.Close()
+	}
+	time.Sleep(time.Millisecond * 50)
+
+	lines := out.Lines()
+	assert.Equal(t, 4, len(lines))
+	// one of every 3 requests to the route is logged
+	assert.Equal(t, true, strings.HasPrefix(lines[0], "127.0.0.1 - gopher ["))
+	assert.Equal(t, true, strings.HasSuffix(lines[0], `] "GET /rooms/0 HTTP/1.1" 200 26 "-" "pluto-test"`))
+	assert.Equal(t, true, strings.Contains(lines[1], `"GET /rooms/3 HTTP/1.1" 200 26`))
+	assert.Equal(t, true, strings.Contains(lines[2], `"GET /fail HTTP/1.1" 503 -`))
+	assert.Equal(t, true, strings.Contains(lines[3], `"GET /fail HTTP/1.1" 503 -`))
+}
+
+func TestAccessLogSamplingInvalid(t *testing.T) {
+	s := server.New(
+		server.Name("sampling"),
+		server.Addr(":8104"),
+		server.AccessLogSampling("/rooms/:id", 0),
+	)
+	assert.Equal(t, "sampling_server: access log sampling of /rooms/:id: n must be at least 1", s.Validate().Error())
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// serveCombined serves gRPC and http routes on ln, with TLS if configured
+// or HTTP/2 in plaintext otherwise
+func (s *Server) serveCombined(ln net.Listener) error {
+	var mux http.Handler = http.NotFoundHandler()
+	if s.cfg.Mux != nil {
+		mux = s.cfg.Mux
+	}
+	h := s.countInflight(s.grpcOrHTTP(mux))
+	if s.cfg.TLSConfig == nil {
+		// Shutdown sends GOAWAY to the h2c connections and closes them, as
+		// they are registered with the http2 server configured for it
+		h2s := &http2.Server{}
+		if err := http2.ConfigureServer(s.httpServer, h2s); err != nil {
+			return err
+		}
+		h = h2c.NewHandler(h, h2s)
+	}
+	s.httpServer.Handler = h
+	// add go routine to WaitGroup
+	s.wg.Add(1)
+	go func(s *Server, ln net.Listener) {
+		defer s.wg.Done()
+		var err error
+		if s.cfg.TLSConfig != nil {
+			// certificates are already in TLSConfig
+			err = s.httpServer.ServeTLS(ln, "", "")
+		} else {
+			err = s.httpServer.Serve(ln)
+		}
+		if err != nil && err != http.ErrServerClosed && err.Error() != errClosing(ln).Error() {
+			s.logger.Error().Msg(err.Error())
+		}
+	}(s, ln)
+	return nil
+}
+
+// grpcOrHTTP routes gRPC and, if enabled, gRPC-Web requests to the grpc
+// server and any other to h
+func (s *Server) grpcOrHTTP(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.cfg.GRPCWeb && isGRPCWeb(r) {
+			s.grpcWeb(w, r)
+			return
+		}
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			s.grpcServer.ServeHTTP(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// waitInflight waits for in-flight requests to complete or ctx to be done.
+// Requests over h2c connections are not waited by http.Server.Shutdown as
+// the connections are hijacked.
+func (s *Server) waitInflight(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for atomic.LoadInt64(&s.inflight) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
@@ -2,15 +2,19 @@ package server
 
 import (
 	"crypto/tls"
-	"log"
-	"regexp"
-	"strconv"
+	"crypto/x509"
+	"io"
+	"os"
 	"sync"
 	"time"
 
+	"github.com/aukbit/pluto/v6/certificate"
 	"github.com/aukbit/pluto/v6/common"
 	"github.com/aukbit/pluto/v6/discovery"
+	"github.com/aukbit/pluto/v6/redact"
 	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/aukbit/pluto/v6/trace"
+	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
 )
 
@@ -20,13 +24,29 @@ type Config struct {
 	Name                     string
 	Description              string
 	Addr                     string // TCP address (e.g. localhost:8000) to listen on, ":http" if empty
-	Format                   string
+	Format                   string // http, https, grpc or combined
 	Mux                      *router.Router
-	TLSConfig                *tls.Config // optional TLS config, used by ListenAndServeTLS
+	TLSConfig                *tls.Config         // optional TLS config, used by ListenAndServeTLS
+	Certificates             *certificate.Source // optional key pair reloaded on change, served through TLSConfig
+	ClientCAs                *x509.CertPool      // certificate authorities verifying client certificates (mutual TLS)
+	ClientAuth               tls.ClientAuthType  // client certificate policy, RequireAndVerifyClientCert if ClientCAs is set
 	GRPCRegister             GRPCRegisterServiceFunc
+	Transcoding              bool     // expose grpc methods as http/json routes, combined format only
+	GRPCWeb                  bool     // accept grpc-web calls from browsers, grpc and combined formats only
+	GRPCWebOrigins           []string // origins allowed to make grpc-web calls with credentials, any without if empty
+	RoutesEndpoint           bool     // list routes on /_routes and document them on /_routes/openapi.json
 	Discovery                discovery.Discovery
+	HealthCheckURL           string        // url checked by discovery, defaults to the server own health handler
+	Tracer                   *trace.Tracer // spans are created and propagated even if nil, but not exported
 	ReadTimeout              time.Duration
 	WriteTimeout             time.Duration
+	PreStopDelay             time.Duration                  // time to keep serving after health reports NOT_SERVING
+	DrainTimeout             time.Duration                  // maximum time to wait for in-flight http requests on stop
+	PanicHandler             PanicHandlerFunc               // called with panics recovered in handlers and methods
+	AccessLogFormat          string                         // json or combined
+	AccessLogOutput          io.Writer                      // where combined access logs are written, os.Stdout by default
+	AccessLogSampling        map[string]zerolog.Sampler     // samplers of access logs by route pattern
+	Redaction                *redact.Policy                 // headers and payload fields redacted from logs
 	mu                       sync.Mutex                     // ensures atomic writes; protects the following fields
 	Middlewares              []router.Middleware            // http middlewares
 	UnaryServerInterceptors  []grpc.UnaryServerInterceptor  // gRPC interceptors
@@ -41,28 +61,59 @@ var (
 	defaultFormat = "http"
 )
 
+// Access log formats
+const (
+	AccessLogJSON     = "json"     // structured request and response lines by the server logger
+	AccessLogCombined = "combined" // Apache combined log format lines
+)
+
 func newConfig() Config {
 	return Config{
-		ID:           common.RandID("srv_", 6),
-		Name:         DefaultName,
-		Addr:         defaultAddr,
-		Format:       defaultFormat,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		ID:              common.RandID("srv_", 6),
+		Name:            DefaultName,
+		Addr:            defaultAddr,
+		Format:          defaultFormat,
+		ReadTimeout:     10 * time.Second,
+		WriteTimeout:    10 * time.Second,
+		DrainTimeout:    10 * time.Second,
+		AccessLogFormat: AccessLogJSON,
+		AccessLogOutput: os.Stdout,
+		Redaction:       redact.New(),
 	}
 }
 
-// Port converts string Addr to int Port
+// Port converts string Addr to int Port, returns 0 if Addr is not valid
+// Note: Server.Validate reports invalid addresses before the server starts
 func (c *Config) Port() int {
-	// support only numeric
-	reg, err := regexp.Compile("[^0-9]+")
-	if err != nil {
-		log.Fatal(err)
-	}
-	safe := reg.ReplaceAllString(c.Addr, "")
-	i, err := strconv.Atoi(safe)
+	i, err := common.Port(c.addr())
 	if err != nil {
-		log.Fatal(err)
+		return 0
 	}
 	return i
 }
+
+// addr returns Addr, defaulting to ":http" or ":https" if empty
+func (c *Config) addr() string {
+	if c.Addr != "" {
+		return c.Addr
+	}
+	return ":" + c.scheme()
+}
+
+// newTLSConfig returns the default server TLS config
+func newTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:               tls.VersionTLS12,
+		CurvePreferences:         []tls.CurveID{tls.CurveP521, tls.CurveP384, tls.CurveP256},
+		PreferServerCipherSuites: true,
+	}
+}
+
+// scheme returns the url scheme of http requests served, https if the
+// server terminates TLS
+func (c *Config) scheme() string {
+	if c.Format == "https" || c.TLSConfig != nil {
+		return "https"
+	}
+	return "http"
+}
@@ -15,3 +15,14 @@ func TestDefaults(t *testing.T) {
 	assert.Equal(t, "http", c.Format)
 	assert.Equal(t, nil, c.Mux)
 }
+
+func TestPort(t *testing.T) {
+	c := newConfig()
+	assert.Equal(t, 8080, c.Port())
+	c.Addr = "localhost:65060"
+	assert.Equal(t, 65060, c.Port())
+	c.Addr = ""
+	assert.Equal(t, 80, c.Port())
+	c.Addr = "8080"
+	assert.Equal(t, 0, c.Port())
+}
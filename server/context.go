@@ -15,6 +15,9 @@ var (
 	// handlers with context.WithValue to access the server that
 	// started the handler. The associated value will be of type *Server.
 	ServerContextKey = &contextKey{"pluto-server"}
+	// PeerIdentityContextKey is a context key. The associated value will be
+	// of type PeerIdentity, see PeerIdentityFromContext.
+	PeerIdentityContextKey = &contextKey{"pluto-peer-identity"}
 )
 
 // FromContext returns server instance from a context
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/http2"
+)
+
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+	// grpcWebTrailerFlag marks the frame carrying trailers in the response body
+	grpcWebTrailerFlag = 0x80
+)
+
+var (
+	// headers browsers are allowed to send on grpc-web calls, unless the
+	// preflight request asks for others
+	grpcWebAllowHeaders = "content-type, x-grpc-web, x-user-agent, grpc-timeout, authorization"
+	// headers browsers are allowed to read from grpc-web responses
+	grpcWebExposeHeaders = "grpc-status, grpc-message, grpc-status-details-bin"
+)
+
+// isGRPCWeb reports whether r is a grpc-web call or its CORS preflight
+func isGRPCWeb(r *http.Request) bool {
+	if r.Method == http.MethodOptions {
+		return r.Header.Get("Access-Control-Request-Method") != "" &&
+			strings.Contains(strings.ToLower(r.Header.Get("Access-Control-Request-Headers")), "x-grpc-web")
+	}
+	return r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), grpcWebContentType)
+}
+
+// grpcWeb handles grpc-web calls, in binary or text mode, by translating them
+// into grpc calls served by the grpc server and its interceptors
+func (s *Server) grpcWeb(w http.ResponseWriter, r *http.Request) {
+	if !s.allowOrigin(w, r) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodOptions {
+		h := w.Header()
+		h.Set("Access-Control-Allow-Methods", http.MethodPost)
+		if rh := r.Header.Get("Access-Control-Request-Headers"); rh != "" {
+			h.Set("Access-Control-Allow-Headers", rh)
+		} else {
+			h.Set("Access-Control-Allow-Headers", grpcWebAllowHeaders)
+		}
+		h.Set("Access-Control-Max-Age", "600")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Access-Control-Expose-Headers", grpcWebExposeHeaders)
+
+	contentType := r.Header.Get("Content-Type")
+	text := strings.HasPrefix(contentType, grpcWebTextContentType)
+	// grpc request
+	req := r.WithContext(r.Context())
+	req.ProtoMajor, req.ProtoMinor, req.Proto = 2, 0, "HTTP/2.0"
+	req.Header = cloneHeader(r.Header)
+	req.Header.Del("Content-Length")
+	if text {
+		req.Header.Set("Content-Type", "application/grpc"+strings.TrimPrefix(contentType, grpcWebTextContentType))
+		req.Body = ioutil.NopCloser(base64.NewDecoder(base64.StdEncoding, r.Body))
+	} else {
+		req.Header.Set("Content-Type", "application/grpc"+strings.TrimPrefix(contentType, grpcWebContentType))
+	}
+	gw := &grpcWebResponseWriter{w: w, header: http.Header{}, contentType: contentType, text: text}
+	s.grpcServer.ServeHTTP(gw, req)
+	gw.finish()
+}
+
+// allowOrigin sets the CORS headers of the grpc-web response if the origin of
+// r is allowed. Any origin is allowed if none is configured, or if * is, but
+// without credentials; only origins configured are allowed credentials.
+func (s *Server) allowOrigin(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// same origin or not a browser
+		return true
+	}
+	h := w.Header()
+	for _, o := range s.cfg.GRPCWebOrigins {
+		if strings.EqualFold(o, origin) {
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Allow-Credentials", "true")
+			h.Add("Vary", "Origin")
+			return true
+		}
+	}
+	for _, o := range s.cfg.GRPCWebOrigins {
+		if o == "*" {
+			h.Set("Access-Control-Allow-Origin", "*")
+			return true
+		}
+	}
+	if len(s.cfg.GRPCWebOrigins) == 0 {
+		h.Set("Access-Control-Allow-Origin", "*")
+		return true
+	}
+	return false
+}
+
+// grpcWebResponseWriter writes the grpc response as a grpc-web response,
+// with trailers sent as the last frame of the body and, in text mode, the
+// body base64 encoded
+type grpcWebResponseWriter struct {
+	w           http.ResponseWriter
+	header      http.Header // headers and trailers set by the grpc server
+	contentType string
+	text        bool
+	wroteHeader bool
+	buf         bytes.Buffer // written since the last flush
+}
+
+func (gw *grpcWebResponseWriter) Header() http.Header {
+	return gw.header
+}
+
+func (gw *grpcWebResponseWriter) WriteHeader(code int) {
+	if gw.wroteHeader {
+		return
+	}
+	gw.wroteHeader = true
+	h := gw.w.Header()
+	for k, vv := range gw.header {
+		if k == "Trailer" || isTrailer(k) {
+			continue
+		}
+		h[k] = vv
+	}
+	h.Set("Content-Type", gw.contentType)
+	h.Del("Content-Length")
+	gw.w.WriteHeader(code)
+}
+
+func (gw *grpcWebResponseWriter) Write(b []byte) (int, error) {
+	gw.WriteHeader(http.StatusOK)
+	return gw.buf.Write(b)
+}
+
+// Flush writes buffered data, base64 encoded in text mode, as padded base64
+// chunks may be concatenated
+func (gw *grpcWebResponseWriter) Flush() {
+	gw.WriteHeader(http.StatusOK)
+	if gw.buf.Len() > 0 {
+		if gw.text {
+			gw.w.Write([]byte(base64.StdEncoding.EncodeToString(gw.buf.Bytes())))
+		} else {
+			gw.w.Write(gw.buf.Bytes())
+		}
+		gw.buf.Reset()
+	}
+	if f, ok := gw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish writes the trailers frame once the grpc call has completed
+func (gw *grpcWebResponseWriter) finish() {
+	var keys []string
+	for k := range gw.header {
+		if isTrailer(k) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		// not handled by the grpc server, e.g. a malformed request
+		gw.Flush()
+		return
+	}
+	sort.Strings(keys)
+	var trailers bytes.Buffer
+	for _, k := range keys {
+		name := strings.ToLower(strings.TrimPrefix(k, http2.TrailerPrefix))
+		for _, v := range gw.header[k] {
+			trailers.WriteString(name + ": " + v + "\r\n")
+		}
+	}
+	hdr := make([]byte, 5)
+	hdr[0] = grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(hdr[1:], uint32(trailers.Len()))
+	gw.Write(hdr)
+	gw.Write(trailers.Bytes())
+	gw.Flush()
+}
+
+// --- Helper functions
+
+// isTrailer reports whether the header k is set by the grpc server as a trailer
+func isTrailer(k string) bool {
+	switch http.CanonicalHeaderKey(k) {
+	case "Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin":
+		return true
+	}
+	return strings.HasPrefix(k, http2.TrailerPrefix)
+}
+
+func cloneHeader(h http.Header) http.Header {
+	c := make(http.Header, len(h))
+	for k, vv := range h {
+		c[k] = append([]string(nil), vv...)
+	}
+	return c
+}
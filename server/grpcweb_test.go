@@ -0,0 +1,224 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/paulormart/assert"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/aukbit/pluto/v6/server"
+	pb "github.com/aukbit/pluto/v6/test/proto"
+)
+
+// countdown.Countdown service streams a reply per second left
+var countdownServiceDesc = grpc.ServiceDesc{
+	ServiceName: "countdown.Countdown",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Count",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			in := new(pb.HelloRequest)
+			if err := stream.RecvMsg(in); err != nil {
+				return err
+			}
+			for _, n := range []string{"3", "2", "1"} {
+				if err := stream.SendMsg(&pb.HelloReply{Message: in.Name + " " + n}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}},
+}
+
+// grpcWebCall posts in to the grpc-web method and returns the messages and
+// trailers of the response
+func grpcWebCall(t *testing.T, method string, text bool, in proto.Message) (*http.Response, [][]byte, string) {
+	b, err := proto.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := append(make([]byte, 5), b...)
+	binary.BigEndian.PutUint32(body[1:], uint32(len(b)))
+	contentType := "application/grpc-web+proto"
+	if text {
+		contentType = "application/grpc-web-text+proto"
+		body = []byte(base64.StdEncoding.EncodeToString(body))
+	}
+	req, err := http.NewRequest("POST", "http://localhost:8090"+method, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Grpc-Web", "1")
+	req.Header.Set("Origin", "http://example.com")
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text {
+		// concatenated padded base64 chunks, decoded by 4 bytes groups
+		var decoded []byte
+		for i := 0; i+4 <= len(data); i += 4 {
+			d, err := base64.StdEncoding.DecodeString(string(data[i : i+4]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded = append(decoded, d...)
+		}
+		data = decoded
+	}
+	var messages [][]byte
+	var trailers string
+	for len(data) >= 5 {
+		n := binary.BigEndian.Uint32(data[1:5])
+		frame := data[5 : 5+n]
+		if data[0]&0x80 != 0 {
+			trailers += string(frame)
+		} else {
+			messages = append(messages, frame)
+		}
+		data = data[5+n:]
+	}
+	return r, messages, trailers
+}
+
+func TestGRPCWeb(t *testing.T) {
+	s := server.New(
+		server.Name("grpcweb"),
+		server.Addr(":8090"),
+		server.GRPCRegister(func(g *grpc.Server) {
+			pb.RegisterGreeterServer(g, &greeter{})
+			g.RegisterService(&countdownServiceDesc, struct{}{})
+		}),
+		server.GRPCWeb("http://example.com"),
+	)
+	go s.Run()
+	defer s.Stop()
+	time.Sleep(time.Millisecond * 100)
+
+	// CORS preflight
+	for origin, status := range map[string]int{
+		"http://example.com": http.StatusNoContent,
+		"http://evil.com":    http.StatusForbidden,
+	} {
+		req, _ := http.NewRequest("OPTIONS", "http://localhost:8090/helloworld.Greeter/SayHello", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+		r, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Body.Close()
+		assert.Equal(t, status, r.StatusCode)
+		if status == http.StatusNoContent {
+			assert.Equal(t, origin, r.Header.Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, "true", r.Header.Get("Access-Control-Allow-Credentials"))
+			assert.Equal(t, "POST", r.Header.Get("Access-Control-Allow-Methods"))
+			assert.Equal(t, "content-type,x-grpc-web", r.Header.Get("Access-Control-Allow-Headers"))
+		}
+	}
+
+	// unary calls in binary and text modes
+	for _, text := range []bool{false, true} {
+		r, messages, trailers := grpcWebCall(t, "/helloworld.Greeter/SayHello", text, &pb.HelloRequest{Name: "Gopher"})
+		assert.Equal(t, http.StatusOK, r.StatusCode)
+		assert.Equal(t, "http://example.com", r.Header.Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, true, strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web"))
+		assert.Equal(t, 1, len(messages))
+		out := &pb.HelloReply{}
+		if err := proto.Unmarshal(messages[0], out); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "Hello Gopher", out.Message)
+		assert.Equal(t, true, strings.Contains(trailers, "grpc-status: 0\r\n"))
+	}
+
+	// grpc errors are sent as trailers
+	_, messages, trailers := grpcWebCall(t, "/helloworld.Greeter/SayGoodbye", false, &pb.HelloRequest{Name: "Gopher"})
+	assert.Equal(t, 0, len(messages))
+	assert.Equal(t, true, strings.Contains(trailers, "grpc-status: 12\r\n"))
+
+	// server streaming
+	_, messages, trailers = grpcWebCall(t, "/countdown.Countdown/Count", true, &pb.HelloRequest{Name: "Gopher"})
+	assert.Equal(t, 3, len(messages))
+	for i, want := range []string{"Gopher 3", "Gopher 2", "Gopher 1"} {
+		out := &pb.HelloReply{}
+		if err := proto.Unmarshal(messages[i], out); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, want, out.Message)
+	}
+	assert.Equal(t, true, strings.Contains(trailers, "grpc-status: 0\r\n"))
+
+	// grpc calls on the same port
+	conn, err := grpc.Dial("localhost:8090", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	res, err := pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "Gopher"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Hello Gopher", res.Message)
+	assert.Equal(t, "SERVING", s.Health().Status.String())
+
+	// streams are recorded in the grpc metrics, as unary calls are
+	w := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	for _, metric := range []string{
+		`pluto_grpc_server_handled_total{code="OK",method="/countdown.Countdown/Count",server="grpcweb_server"} 1`,
+		`pluto_grpc_server_handling_seconds_count{code="OK",method="/countdown.Countdown/Count",server="grpcweb_server"} 1`,
+	} {
+		if !strings.Contains(w.Body.String(), metric) {
+			t.Errorf("%s not exposed", metric)
+		}
+	}
+}
+
+func TestGRPCWebAnyOrigin(t *testing.T) {
+	s := server.New(
+		server.Name("grpcweb_any"),
+		server.Addr(":8103"),
+		server.GRPCRegister(func(g *grpc.Server) {
+			pb.RegisterGreeterServer(g, &greeter{})
+		}),
+		server.GRPCWeb(),
+	)
+	go s.Run()
+	defer s.Stop()
+	time.Sleep(time.Millisecond * 100)
+
+	// origins not configured are allowed, but without credentials
+	req, _ := http.NewRequest("OPTIONS", "http://localhost:8103/helloworld.Greeter/SayHello", nil)
+	req.Header.Set("Origin", "http://evil.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	assert.Equal(t, http.StatusNoContent, r.StatusCode)
+	assert.Equal(t, "*", r.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "", r.Header.Get("Access-Control-Allow-Credentials"))
+}
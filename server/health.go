@@ -3,11 +3,19 @@ package server
 import (
 	"net/http"
 
+	"github.com/aukbit/pluto/v6/certificate"
 	"github.com/aukbit/pluto/v6/reply"
 	"golang.org/x/net/context"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
+// healthResponse is the health of the server together with the status of
+// its certificate, if reloaded from a certificate source
+type healthResponse struct {
+	*healthpb.HealthCheckResponse
+	Certificate *certificate.Status `json:"certificate,omitempty"`
+}
+
 func healthHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	s := FromContext(ctx)
@@ -17,5 +25,10 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 		reply.Json(w, r, http.StatusTooManyRequests, hcr)
 		return
 	}
+	if s.cfg.Certificates != nil {
+		st := s.cfg.Certificates.Status()
+		reply.Json(w, r, http.StatusOK, healthResponse{hcr, &st})
+		return
+	}
 	reply.Json(w, r, http.StatusOK, hcr)
 }
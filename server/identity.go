@@ -0,0 +1,70 @@
+package server
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// PeerIdentity is the identity of a client verified with mutual TLS, taken
+// from its certificate
+type PeerIdentity struct {
+	Subject        string // distinguished name, e.g. CN=gopher,O=aukbit
+	CommonName     string
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []string
+	URIs           []string // e.g. spiffe://aukbit.com/gopher
+}
+
+// PeerIdentityFromContext returns the verified identity of the client of an
+// http request or grpc call, false if the client did not present a verified
+// certificate
+func PeerIdentityFromContext(ctx context.Context) (PeerIdentity, bool) {
+	id, ok := ctx.Value(PeerIdentityContextKey).(PeerIdentity)
+	return id, ok
+}
+
+// withPeerIdentity returns a copy of ctx with the verified identity of the
+// client of the connection state cs, if any
+func withPeerIdentity(ctx context.Context, cs *tls.ConnectionState) context.Context {
+	if cs == nil || len(cs.VerifiedChains) == 0 || len(cs.VerifiedChains[0]) == 0 {
+		return ctx
+	}
+	cert := cs.VerifiedChains[0][0]
+	id := PeerIdentity{
+		Subject:        cert.Subject.String(),
+		CommonName:     cert.Subject.CommonName,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+	}
+	for _, ip := range cert.IPAddresses {
+		id.IPAddresses = append(id.IPAddresses, ip.String())
+	}
+	for _, u := range cert.URIs {
+		id.URIs = append(id.URIs, u.String())
+	}
+	return context.WithValue(ctx, PeerIdentityContextKey, id)
+}
+
+// withRequestPeerIdentity returns a copy of the context of r with the
+// verified identity of the client, if any
+func withRequestPeerIdentity(r *http.Request) context.Context {
+	return withPeerIdentity(r.Context(), r.TLS)
+}
+
+// withGRPCPeerIdentity returns a copy of ctx with the verified identity of the
+// grpc peer, if any
+func withGRPCPeerIdentity(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		return withPeerIdentity(ctx, &info.State)
+	}
+	return ctx
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/aukbit/pluto/v6/trace"
 	"github.com/rs/zerolog"
 
 	"golang.org/x/net/context"
@@ -13,7 +14,7 @@ import (
 
 func serverUnaryServerInterceptor(s *Server) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		ctx = s.WithContext(ctx)
+		ctx = s.WithContext(withGRPCPeerIdentity(ctx))
 		return handler(ctx, req)
 	}
 }
@@ -31,20 +32,25 @@ func loggerUnaryServerInterceptor(s *Server) grpc.UnaryServerInterceptor {
 		sublogger := s.logger.With().
 			Str("eid", e).
 			Str("method", info.FullMethod).Logger()
-		sublogger.Info().
-			Str("data", fmt.Sprintf("%v", req)).
-			Dict("peer", zerolog.Dict().
-				Str("addr", fmt.Sprintf("%v", p.Addr)).
-				Str("auth", fmt.Sprintf("%v", p.AuthInfo))).
+		sublogger = trace.Logger(ctx, sublogger)
+		l := sublogger.Info()
+		if data, ok := s.cfg.Redaction.Payload(info.FullMethod, req); ok {
+			l = l.Str("data", data)
+		}
+		l.Dict("peer", zerolog.Dict().
+			Str("addr", fmt.Sprintf("%v", p.Addr)).
+			Str("auth", fmt.Sprintf("%v", p.AuthInfo))).
 			Msgf("call %s received from %v", info.FullMethod, p.Addr)
 
 		// also nice to have a logger available in context
 		ctx = sublogger.WithContext(ctx)
 		h, err := handler(ctx, req)
 		end := time.Now()
-		sublogger.Info().
-			Str("data", fmt.Sprintf("%v", h)).
-			Msgf("response %s sent to %v - duration: %v", info.FullMethod, p.Addr, end.Sub(start))
+		l = sublogger.Info()
+		if data, ok := s.cfg.Redaction.Payload(info.FullMethod, h); ok {
+			l = l.Str("data", data)
+		}
+		l.Msgf("response %s sent to %v - duration: %v", info.FullMethod, p.Addr, end.Sub(start))
 		return h, err
 	}
 }
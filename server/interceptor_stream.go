@@ -3,6 +3,7 @@ package server
 import (
 	"fmt"
 
+	"github.com/aukbit/pluto/v6/trace"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/peer"
@@ -11,7 +12,7 @@ import (
 func serverStreamServerInterceptor(s *Server) grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		ctx := ss.Context()
-		ctx = s.WithContext(ctx)
+		ctx = s.WithContext(withGRPCPeerIdentity(ctx))
 		// wrap context
 		wrapped := WrapServerStreamWithContext(ss)
 		wrapped.SetContext(ctx)
@@ -29,6 +30,7 @@ func loggerStreamServerInterceptor(s *Server) grpc.StreamServerInterceptor {
 		sublogger := s.logger.With().
 			Str("eid", e).
 			Str("method", info.FullMethod).Logger()
+		sublogger = trace.Logger(ctx, sublogger)
 		sublogger.Info().
 			Dict("peer", zerolog.Dict().
 				Str("addr", fmt.Sprintf("%v", p.Addr)).
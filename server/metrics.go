@@ -0,0 +1,117 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/aukbit/pluto/v6/server/router"
+)
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pluto_http_requests_total",
+			Help: "Total number of http requests handled, by server, route, method and status code.",
+		},
+		[]string{"server", "route", "method", "code"},
+	)
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "pluto_http_request_duration_seconds",
+			Help:    "Latency of http requests handled, by server, route, method and status code.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"server", "route", "method", "code"},
+	)
+	grpcServerHandledTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pluto_grpc_server_handled_total",
+			Help: "Total number of gRPC calls handled, by server, method and status code.",
+		},
+		[]string{"server", "method", "code"},
+	)
+	grpcServerHandlingSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "pluto_grpc_server_handling_seconds",
+			Help:    "Latency of gRPC calls handled, by server, method and status code.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"server", "method", "code"},
+	)
+	panicsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pluto_panics_total",
+			Help: "Total number of panics recovered in http handlers and gRPC methods, by server and kind.",
+		},
+		[]string{"server", "kind"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		httpRequestsTotal,
+		httpRequestDuration,
+		grpcServerHandledTotal,
+		grpcServerHandlingSeconds,
+		panicsTotal,
+	)
+}
+
+// metricsMiddleware Middleware that records request count and latency
+// labelled by the route pattern matched
+func metricsMiddleware(s *Server) router.Middleware {
+	return func(h router.HandlerFunc) router.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/_health", "/healthz/ready", "/healthz/live", "/metrics":
+				h.ServeHTTP(w, r)
+			default:
+				start := time.Now()
+				sw := newStatusWriter(w)
+				h.ServeHTTP(sw, r)
+				code := strconv.Itoa(sw.status)
+				route := router.PatternFromContext(r.Context())
+				httpRequestsTotal.WithLabelValues(s.Name(), route, r.Method, code).Inc()
+				httpRequestDuration.WithLabelValues(s.Name(), route, r.Method, code).Observe(time.Since(start).Seconds())
+			}
+		}
+	}
+}
+
+// metricsUnaryServerInterceptor records call count and latency labelled by
+// the gRPC full method
+func metricsUnaryServerInterceptor(s *Server) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod == "/grpc.health.v1.Health/Check" {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		h, err := handler(ctx, req)
+		code := status.Code(err).String()
+		grpcServerHandledTotal.WithLabelValues(s.Name(), info.FullMethod, code).Inc()
+		grpcServerHandlingSeconds.WithLabelValues(s.Name(), info.FullMethod, code).Observe(time.Since(start).Seconds())
+		return h, err
+	}
+}
+
+// metricsStreamServerInterceptor records call count and latency of streams,
+// until the handler returns, labelled by the gRPC full method
+func metricsStreamServerInterceptor(s *Server) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if info.FullMethod == "/grpc.health.v1.Health/Watch" {
+			return handler(srv, ss)
+		}
+		start := time.Now()
+		err := handler(srv, ss)
+		code := status.Code(err).String()
+		grpcServerHandledTotal.WithLabelValues(s.Name(), info.FullMethod, code).Inc()
+		grpcServerHandlingSeconds.WithLabelValues(s.Name(), info.FullMethod, code).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
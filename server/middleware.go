@@ -3,19 +3,23 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
 	"google.golang.org/grpc/metadata"
 
 	"github.com/aukbit/pluto/v6/common"
 	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/aukbit/pluto/v6/trace"
 	"github.com/rs/zerolog"
 )
 
 func serverMiddleware(s *Server) router.Middleware {
 	return func(h router.HandlerFunc) router.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
-			ctx := s.WithContext(r.Context())
+			ctx := s.WithContext(withRequestPeerIdentity(r))
 			h.ServeHTTP(w, r.WithContext(ctx))
 		}
 	}
@@ -47,31 +51,56 @@ func eidMiddleware(s *Server) router.Middleware {
 }
 
 // loggerMiddleware Middleware that adds logger instance
-// available in handlers context and logs request
+// available in handlers context and logs request and response
 func loggerMiddleware(s *Server) router.Middleware {
 	return func(h router.HandlerFunc) router.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			switch r.URL.Path {
-			case "/_health", "/healthz/ready", "/healthz/live":
+			case "/_health", "/healthz/ready", "/healthz/live", "/metrics":
 				h.ServeHTTP(w, r)
 			default:
+				start := time.Now()
 				ctx := r.Context()
-				header := zerolog.Dict()
-				for k, v := range r.Header {
-					header.Strs(k, v)
+				route := router.PatternFromContext(ctx)
+				sampled := true
+				if sampler, ok := s.cfg.AccessLogSampling[route]; ok {
+					sampled = sampler.Sample(zerolog.InfoLevel)
 				}
 				e := eidFromIncomingContext(ctx)
 				// sets new logger instance with eid
 				sublogger := s.logger.With().Str("eid", e).Logger()
-				sublogger.Info().Str("method", r.Method).
-					Str("url", r.URL.String()).
-					Str("proto", r.Proto).
-					Str("remote_addr", r.RemoteAddr).
-					Dict("header", header).
-					Msg(fmt.Sprintf("%v %v %v", r.Method, r.URL, r.Proto))
-					// also nice to have a logger available in context
+				sublogger = trace.Logger(ctx, sublogger)
+				if sampled && s.cfg.AccessLogFormat == AccessLogJSON {
+					header := zerolog.Dict()
+					for k, v := range r.Header {
+						header.Strs(k, s.cfg.Redaction.Header(k, v))
+					}
+					sublogger.Info().Str("method", r.Method).
+						Str("url", r.URL.String()).
+						Str("proto", r.Proto).
+						Str("remote_addr", r.RemoteAddr).
+						Dict("header", header).
+						Msg(fmt.Sprintf("%v %v %v", r.Method, r.URL, r.Proto))
+				}
+				// also nice to have a logger available in context
 				ctx = sublogger.WithContext(ctx)
-				h.ServeHTTP(w, r.WithContext(ctx))
+				sw := newStatusWriter(w)
+				h.ServeHTTP(sw, r.WithContext(ctx))
+				if !sampled && sw.status < http.StatusInternalServerError {
+					return
+				}
+				switch s.cfg.AccessLogFormat {
+				case AccessLogCombined:
+					fmt.Fprintln(s.cfg.AccessLogOutput, combinedLogLine(r, sw, start))
+				default:
+					d := time.Since(start)
+					sublogger.Info().Str("method", r.Method).
+						Str("route", route).
+						Int("status", sw.status).
+						Int("size", sw.size).
+						Dur("duration", d).
+						Msg(fmt.Sprintf("%v %v %d %v", r.Method, r.URL, sw.status, d))
+				}
 			}
 		}
 	}
@@ -127,3 +156,32 @@ func eidFromOutgoingContext(ctx context.Context) string {
 	}
 	return md["eid"][0]
 }
+
+// combinedLogLine returns the request in the Apache combined log format
+// e.g. 127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /a.gif HTTP/1.0" 200 2326 "http://example.com/" "Mozilla/4.08"
+func combinedLogLine(r *http.Request, sw *statusWriter, start time.Time) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = u
+	}
+	size := "-"
+	if sw.size > 0 {
+		size = strconv.Itoa(sw.size)
+	}
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s %q %q",
+		host, user, start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method, r.URL.RequestURI(), r.Proto, sw.status, size,
+		orDash(r.Referer()), orDash(r.UserAgent()))
+}
+
+// orDash returns v, or - if empty as in common log formats
+func orDash(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
@@ -2,12 +2,16 @@ package server
 
 import (
 	"crypto/tls"
-	"log"
+	"fmt"
+	"io"
 	"time"
 
+	"github.com/aukbit/pluto/v6/certificate"
 	"github.com/aukbit/pluto/v6/common"
 	"github.com/aukbit/pluto/v6/discovery"
+	"github.com/aukbit/pluto/v6/redact"
 	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/aukbit/pluto/v6/trace"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
 )
@@ -60,20 +64,62 @@ func Mux(m *router.Router) Option {
 }
 
 // TLSConfig server multiplexer
+// Note: if the key pair can not be loaded the error is reported by Run
 func TLSConfig(certFile, keyFile string) Option {
 	return optionFunc(func(s *Server) {
 		cer, err := tls.LoadX509KeyPair(certFile, keyFile)
 		if err != nil {
-			log.Printf("ERROR tls.LoadX509KeyPair %v", err)
+			s.errs = append(s.errs, fmt.Errorf("tls config: %v", err))
 			return
 		}
-		s.cfg.TLSConfig = &tls.Config{
-			MinVersion:               tls.VersionTLS12,
-			CurvePreferences:         []tls.CurveID{tls.CurveP521, tls.CurveP384, tls.CurveP256},
-			PreferServerCipherSuites: true,
-			Certificates:             []tls.Certificate{cer},
+		s.cfg.Certificates = nil
+		s.cfg.TLSConfig = newTLSConfig()
+		s.cfg.TLSConfig.Certificates = []tls.Certificate{cer}
+		if s.cfg.Format != "combined" && s.cfg.Format != "grpc" {
+			s.cfg.Format = "https"
 		}
-		s.cfg.Format = "https"
+	})
+}
+
+// CertificateSource serves TLS with the key pair of src, reloaded whenever
+// its files change or the process receives SIGHUP, as an alternative to
+// TLSConfig. The server reports in health when the certificate is expiring.
+func CertificateSource(src *certificate.Source) Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.Certificates = src
+		s.cfg.TLSConfig = newTLSConfig()
+		s.cfg.TLSConfig.GetCertificate = src.GetCertificate
+		if s.cfg.Format != "combined" && s.cfg.Format != "grpc" {
+			s.cfg.Format = "https"
+		}
+	})
+}
+
+// ClientCAs requires clients to present a certificate signed by one of the
+// certificate authorities in caFiles (mutual TLS). The verified identity of
+// the client is available with PeerIdentityFromContext. Requires TLSConfig.
+// Note: grpc servers present their own certificate when checking their
+// health, it must then be valid for client authentication too.
+func ClientCAs(caFiles ...string) Option {
+	return optionFunc(func(s *Server) {
+		pool, err := common.CertPool(caFiles...)
+		if err != nil {
+			s.errs = append(s.errs, fmt.Errorf("client CAs: %v", err))
+			return
+		}
+		s.cfg.ClientCAs = pool
+		if s.cfg.ClientAuth == tls.NoClientCert {
+			s.cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	})
+}
+
+// ClientAuth sets the policy for client certificates, e.g.
+// tls.VerifyClientCertIfGiven to accept clients without a certificate.
+// The default, once ClientCAs is set, is tls.RequireAndVerifyClientCert.
+func ClientAuth(t tls.ClientAuthType) Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.ClientAuth = t
 	})
 }
 
@@ -81,7 +127,47 @@ func TLSConfig(certFile, keyFile string) Option {
 func GRPCRegister(fn GRPCRegisterServiceFunc) Option {
 	return optionFunc(func(s *Server) {
 		s.cfg.GRPCRegister = fn
-		s.cfg.Format = "grpc"
+		if s.cfg.Format != "combined" {
+			s.cfg.Format = "grpc"
+		}
+	})
+}
+
+// Combined serves gRPC and http routes on the same port. Requests over
+// HTTP/2 with content-type application/grpc are handled by the gRPC
+// services registered with GRPCRegister, all others by the router. Without
+// TLSConfig, HTTP/2 is served in plaintext (h2c).
+func Combined() Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.Format = "combined"
+	})
+}
+
+// Transcoding exposes the unary methods of the grpc services registered as
+// http/json routes, as annotated with google.api.http or, if not annotated,
+// as POST /package.Service/Method. Requires the Combined format.
+func Transcoding() Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.Transcoding = true
+	})
+}
+
+// GRPCWeb accepts gRPC-Web calls from browsers, in binary and text modes,
+// besides gRPC calls on the same port, with CORS allowed from origins or
+// from any origin if none is given. Only calls from origins given are made
+// with credentials, e.g. cookies. Requires the grpc or Combined format.
+func GRPCWeb(origins ...string) Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.GRPCWeb = true
+		s.cfg.GRPCWebOrigins = append(s.cfg.GRPCWebOrigins, origins...)
+	})
+}
+
+// RoutesEndpoint lists the http routes served on /_routes, with their
+// params and metadata, and documents them on /_routes/openapi.json
+func RoutesEndpoint() Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.RoutesEndpoint = true
 	})
 }
 
@@ -119,6 +205,21 @@ func Discovery(d discovery.Discovery) Option {
 	})
 }
 
+// HealthCheckURL url that the service discovery checks for the server health
+// e.g. the pluto service health server, http://10.0.0.1:9090/_health/server/name
+func HealthCheckURL(u string) Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.HealthCheckURL = u
+	})
+}
+
+// Tracer sets the tracer exporting the spans of http routes and grpc methods
+func Tracer(t *trace.Tracer) Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.Tracer = t
+	})
+}
+
 // Logger sets a shallow copy from an input logger
 func Logger(l zerolog.Logger) Option {
 	return optionFunc(func(s *Server) {
@@ -141,3 +242,73 @@ func WriteTimeout(t time.Duration) Option {
 		s.cfg.WriteTimeout = t
 	})
 }
+
+// PreStopDelay is the time an http server keeps accepting requests after its
+// health is set as NOT_SERVING on stop, so load balancers can notice it before
+// the listener is closed. The default is no delay.
+func PreStopDelay(d time.Duration) Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.PreStopDelay = d
+	})
+}
+
+// DrainTimeout is the maximum duration an http server waits for in-flight
+// requests to complete on stop, remaining requests are then cut off.
+// The default timeout is 10 seconds.
+func DrainTimeout(d time.Duration) Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.DrainTimeout = d
+	})
+}
+
+// PanicHandler sets a function called with the value and stack of panics
+// recovered in http handlers and grpc methods, besides logging them
+func PanicHandler(fn PanicHandlerFunc) Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.PanicHandler = fn
+	})
+}
+
+// AccessLogFormat sets the format of http access logs, AccessLogJSON or
+// AccessLogCombined. The default is AccessLogJSON.
+func AccessLogFormat(f string) Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.AccessLogFormat = f
+	})
+}
+
+// AccessLogOutput sets where access logs in the combined format are written.
+// The default is os.Stdout.
+func AccessLogOutput(w io.Writer) Option {
+	return optionFunc(func(s *Server) {
+		s.cfg.AccessLogOutput = w
+	})
+}
+
+// AccessLogSampling logs only one of every n requests to the route pattern,
+// e.g. /rooms/:id. Responses with server errors are always logged.
+// n must be at least 1.
+func AccessLogSampling(route string, n uint32) Option {
+	return optionFunc(func(s *Server) {
+		if n < 1 {
+			s.errs = append(s.errs, fmt.Errorf("access log sampling of %s: n must be at least 1", route))
+			return
+		}
+		if s.cfg.AccessLogSampling == nil {
+			s.cfg.AccessLogSampling = make(map[string]zerolog.Sampler)
+		}
+		s.cfg.AccessLogSampling[route] = &zerolog.BasicSampler{N: n}
+	})
+}
+
+// Redaction sets what is redacted from request logs. The default policy
+// redacts credentials in headers and fields annotated as sensitive, with
+// payloads capped to 1024 bytes, which a nil policy leaves in place.
+func Redaction(p *redact.Policy) Option {
+	return optionFunc(func(s *Server) {
+		if p == nil {
+			return
+		}
+		s.cfg.Redaction = p
+	})
+}
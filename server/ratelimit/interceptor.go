@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor limits grpc calls, failing those exceeding the
+// limit with ResourceExhausted and a retry-after header
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if res := l.allowGRPC(ctx, info.FullMethod); !res.Allowed {
+			grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter(res.RetryAfter)))
+			return nil, errExhausted(res)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// --- Helper functions
+
+func (l *Limiter) allowGRPC(ctx context.Context, method string) Result {
+	req := &Request{
+		Context: ctx,
+		Route:   method,
+		Metadata: func(key string) string {
+			md, _ := metadata.FromIncomingContext(ctx)
+			if v := md.Get(key); len(v) > 0 {
+				return v[0]
+			}
+			return ""
+		},
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		req.Addr = p.Addr.String()
+	}
+	res, _ := l.allow(req)
+	return res
+}
+
+func errExhausted(res Result) error {
+	return status.Error(codes.ResourceExhausted,
+		fmt.Sprintf("too many requests, retry after %s seconds", retryAfter(res.RetryAfter)))
+}
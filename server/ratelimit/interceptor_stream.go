@@ -0,0 +1,18 @@
+package ratelimit
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// StreamServerInterceptor limits grpc streams when opened, failing those
+// exceeding the limit with ResourceExhausted and a retry-after header
+func (l *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if res := l.allowGRPC(ss.Context(), info.FullMethod); !res.Allowed {
+			ss.SetHeader(metadata.Pairs("retry-after", retryAfter(res.RetryAfter)))
+			return errExhausted(res)
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"net"
+	"strings"
+
+	"github.com/aukbit/pluto/v6/auth/jws"
+	"github.com/aukbit/pluto/v6/auth/jwt"
+	"golang.org/x/net/context"
+)
+
+// Request is an http request or grpc call being limited
+type Request struct {
+	Context  context.Context
+	Route    string              // route pattern, e.g. GET /rooms/:id, or grpc method
+	Addr     string              // remote address
+	Metadata func(string) string // returns the http header or grpc metadata value of a key
+}
+
+// KeyFunc returns the key a request is limited by, requests with an empty
+// key are not limited
+type KeyFunc func(*Request) string
+
+var (
+	// ByIP limits requests by remote IP
+	ByIP KeyFunc = func(r *Request) string {
+		host, _, err := net.SplitHostPort(r.Addr)
+		if err != nil {
+			return r.Addr
+		}
+		return host
+	}
+
+	// ByPrincipal limits requests by the principal of the JWT token available
+	// in the request context, see jwt.TokenFromContext, once the token is
+	// verified with the public key available in the context, see
+	// jwt.PublicKeyFromContext. Requests without a verified principal are
+	// limited by IP, so that forged or malformed tokens do not get a limit of
+	// their own.
+	// Note: the public key must be in the context before the limiter runs,
+	// e.g. with jwt.Middleware or jwt.RsaUnaryServerInterceptor.
+	ByPrincipal KeyFunc = func(r *Request) string {
+		t, ok := jwt.TokenFromContext(r.Context)
+		if !ok || jwt.Verify(r.Context, t) != nil {
+			return ByIP(r)
+		}
+		c, err := jws.Decode(t)
+		if err != nil || c.Prn == "" {
+			return ByIP(r)
+		}
+		return "prn:" + c.Prn
+	}
+
+	// ByRoute limits requests by route pattern or grpc method, regardless of
+	// who makes them
+	ByRoute KeyFunc = func(r *Request) string {
+		return r.Route
+	}
+)
+
+// ByAPIKey limits requests by the API key in the http header or grpc
+// metadata key, e.g. X-Api-Key
+func ByAPIKey(key string) KeyFunc {
+	return func(r *Request) string {
+		if v := r.Metadata(key); v != "" {
+			return "key:" + v
+		}
+		return ""
+	}
+}
+
+// Compose limits requests by all keys together, e.g. Compose(ByRoute, ByIP)
+// limits the requests of each IP to each route
+func Compose(keys ...KeyFunc) KeyFunc {
+	return func(r *Request) string {
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			if parts[i] = k(r); parts[i] == "" {
+				return ""
+			}
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// FirstOf limits requests by the first non empty key, e.g.
+// FirstOf(ByAPIKey("X-Api-Key"), ByIP) limits requests without key by IP
+func FirstOf(keys ...KeyFunc) KeyFunc {
+	return func(r *Request) string {
+		for _, k := range keys {
+			if v := k(r); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+}
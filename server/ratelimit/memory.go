@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// MemoryStore keeps a token bucket per key in memory, limiting requests of
+// a single server instance
+type MemoryStore struct {
+	mu      sync.Mutex // protects the following fields
+	buckets map[string]*bucket
+	swept   time.Time // last time full buckets were removed
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time // last time tokens were refilled
+	rate   float64   // tokens refilled per second
+	burst  float64   // tokens the bucket holds when full
+}
+
+// NewMemoryStore returns an empty in memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of key, refilled at the limit rate up
+// to its burst
+func (s *MemoryStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.burst()), last: now}
+		s.buckets[key] = b
+	}
+	b.rate = float64(limit.Rate) / limit.Period.Seconds()
+	b.burst = float64(limit.burst())
+	b.refill(now)
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		return Result{Allowed: false, RetryAfter: wait}, nil
+	}
+	b.tokens--
+	return Result{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+// refill adds the tokens refilled since the last time, up to the burst
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// sweep removes, at most once per minute, buckets that are full again under
+// their own limit as they are no different from new ones
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+	for k, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst {
+			delete(s.buckets, k)
+		}
+	}
+}
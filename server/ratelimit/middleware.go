@@ -0,0 +1,39 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aukbit/pluto/v6/reply"
+	"github.com/aukbit/pluto/v6/server/router"
+)
+
+// Middleware limits http requests, replying 429 Too Many Requests with a
+// Retry-After header to those exceeding the limit
+func (l *Limiter) Middleware() router.Middleware {
+	return func(h router.HandlerFunc) router.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			res, checked := l.allow(&Request{
+				Context:  ctx,
+				Route:    r.Method + " " + router.PatternFromContext(ctx),
+				Addr:     r.RemoteAddr,
+				Metadata: r.Header.Get,
+			})
+			if checked {
+				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(l.limit.burst()))
+				w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			}
+			if !res.Allowed {
+				w.Header().Set("Retry-After", retryAfter(res.RetryAfter))
+				reply.Json(w, r, http.StatusTooManyRequests, &router.Err{
+					Type:    "rate_limit_error",
+					Message: fmt.Sprintf("too many requests, retry after %s seconds", retryAfter(res.RetryAfter)),
+				})
+				return
+			}
+			h.ServeHTTP(w, r)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package ratelimit
+
+// Option is used to set options for the limiter.
+type Option interface {
+	apply(*Limiter)
+}
+
+// optionFunc wraps a func so it satisfies the Option interface.
+type optionFunc func(*Limiter)
+
+func (f optionFunc) apply(l *Limiter) {
+	f(l)
+}
+
+// Key sets the key requests are limited by, e.g. ByPrincipal or
+// Compose(ByRoute, ByIP). The default is ByIP.
+func Key(k KeyFunc) Option {
+	return optionFunc(func(l *Limiter) {
+		l.key = k
+	})
+}
+
+// WithStore sets the store keeping the requests made, e.g. a RedisStore
+// shared by all instances of a service. The default is a MemoryStore.
+func WithStore(s Store) Option {
+	return optionFunc(func(l *Limiter) {
+		l.store = s
+	})
+}
+
+// Prefix sets the prefix of keys in the store, so limiters sharing a store
+// keep separate counts. The default is ratelimit.
+func Prefix(p string) Option {
+	return optionFunc(func(l *Limiter) {
+		l.prefix = p
+	})
+}
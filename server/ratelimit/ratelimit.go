@@ -0,0 +1,111 @@
+// Package ratelimit limits the rate of http requests and grpc calls a server
+// accepts, keyed by remote IP, JWT principal, API key or route, with state
+// kept in memory or in Redis.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/net/context"
+)
+
+// Limit is the number of requests allowed per period for each key
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int // requests allowed at once, defaults to Rate
+}
+
+// PerSecond returns a limit of rate requests per second
+func PerSecond(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Second}
+}
+
+// PerMinute returns a limit of rate requests per minute
+func PerMinute(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Minute}
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Rate
+}
+
+// Result of a request checked against a limit
+type Result struct {
+	Allowed    bool
+	Remaining  int           // requests left for the key
+	RetryAfter time.Duration // time until a request is allowed again, if not allowed
+}
+
+// Store keeps the requests made by each key
+type Store interface {
+	// Allow records a request for key if allowed under limit
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Limiter limits requests by key, see Middleware, UnaryServerInterceptor
+// and StreamServerInterceptor
+type Limiter struct {
+	limit  Limit
+	key    KeyFunc
+	store  Store
+	prefix string
+}
+
+// New returns a limiter of requests to limit per remote IP, in memory,
+// unless configured otherwise with opts. It panics if the rate of limit is
+// less than 1, its period is not positive or its burst is negative.
+func New(limit Limit, opts ...Option) *Limiter {
+	switch {
+	case limit.Rate < 1:
+		panic(fmt.Sprintf("Rate limit must allow at least 1 request, got %d", limit.Rate))
+	case limit.Period <= 0:
+		panic(fmt.Sprintf("Rate limit period must be positive, got %v", limit.Period))
+	case limit.Burst < 0:
+		panic(fmt.Sprintf("Rate limit burst must not be negative, got %d", limit.Burst))
+	}
+	l := &Limiter{
+		limit:  limit,
+		key:    ByIP,
+		prefix: "ratelimit",
+	}
+	for _, opt := range opts {
+		opt.apply(l)
+	}
+	if l.store == nil {
+		l.store = NewMemoryStore()
+	}
+	return l
+}
+
+// allow checks req against the limit, requests without key or checked while
+// the store is failing are allowed
+func (l *Limiter) allow(req *Request) (Result, bool) {
+	key := l.key(req)
+	if key == "" {
+		return Result{Allowed: true}, false
+	}
+	res, err := l.store.Allow(req.Context, l.prefix+":"+key, l.limit)
+	if err != nil {
+		zerolog.Ctx(req.Context).Error().Msg(fmt.Sprintf("rate limit not checked: %v", err))
+		return Result{Allowed: true}, false
+	}
+	if !res.Allowed {
+		zerolog.Ctx(req.Context).Warn().Str("key", key).
+			Msg(fmt.Sprintf("rate limit of %d requests per %v exceeded, retry after %v", l.limit.Rate, l.limit.Period, res.RetryAfter))
+	}
+	return res, true
+}
+
+// retryAfter returns d in whole seconds, rounded up, as in the Retry-After
+// header
+func retryAfter(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,209 @@
+package ratelimit
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aukbit/pluto/v6/auth/jwt"
+	"github.com/aukbit/pluto/v6/reply"
+	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/go-redis/redis"
+	"github.com/paulormart/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// testStore checks s against a limit whose burst differs from its rate, with
+// the clock of s set by now
+func testStore(t *testing.T, s Store, now *time.Time, prefix string) {
+	limit := Limit{Rate: 2, Period: time.Second, Burst: 3}
+	ctx := context.Background()
+	// burst
+	for i := 2; i >= 0; i-- {
+		res, err := s.Allow(ctx, prefix+"gopher", limit)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, res.Allowed)
+		assert.Equal(t, i, res.Remaining)
+	}
+	res, _ := s.Allow(ctx, prefix+"gopher", limit)
+	assert.Equal(t, false, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+	// other keys have their own bucket
+	res, _ = s.Allow(ctx, prefix+"other", limit)
+	assert.Equal(t, true, res.Allowed)
+	// refilled at the limit rate, not at its burst
+	*now = now.Add(500 * time.Millisecond)
+	res, _ = s.Allow(ctx, prefix+"gopher", limit)
+	assert.Equal(t, true, res.Allowed)
+	res, _ = s.Allow(ctx, prefix+"gopher", limit)
+	assert.Equal(t, false, res.Allowed)
+	*now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		res, _ = s.Allow(ctx, prefix+"gopher", limit)
+		assert.Equal(t, true, res.Allowed)
+	}
+	res, _ = s.Allow(ctx, prefix+"gopher", limit)
+	assert.Equal(t, false, res.Allowed)
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	testStore(t, s, &now, "")
+	// full buckets are swept
+	now = now.Add(time.Minute)
+	s.Allow(context.Background(), "new", PerSecond(2))
+	assert.Equal(t, 1, len(s.buckets))
+}
+
+func TestMemoryStoreShared(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+	strict := Limit{Rate: 1, Period: time.Hour}
+	res, _ := s.Allow(ctx, "strict:gopher", strict)
+	assert.Equal(t, true, res.Allowed)
+	// a sweep under a looser limit keeps the buckets of the strict one
+	now = now.Add(time.Minute)
+	s.Allow(ctx, "loose:gopher", PerSecond(100))
+	res, _ = s.Allow(ctx, "strict:gopher", strict)
+	assert.Equal(t, false, res.Allowed)
+}
+
+// go test ./server/ratelimit -run TestRedisStore with REDIS_ADDR=localhost:6379
+func TestRedisStore(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR not set")
+	}
+	clt := redis.NewClient(&redis.Options{Addr: addr})
+	defer clt.Close()
+	s := NewRedisStore(clt)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	testStore(t, s, &now, fmt.Sprintf("ratelimit_test:%d:", now.UnixNano()))
+}
+
+func TestKeys(t *testing.T) {
+	prv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.NewTokenWithPrivateKey(&jwt.ClaimSet{Principal: "gopher", Expiration: 60}, prv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), jwt.PublicKeyContextKey, &prv.PublicKey)
+	header := http.Header{"X-Api-Key": {"abc"}}
+	r := &Request{Context: context.WithValue(ctx, jwt.TokenContextKey, token), Route: "GET /rooms/:id", Addr: "10.0.0.1:52000", Metadata: header.Get}
+	assert.Equal(t, "10.0.0.1", ByIP(r))
+	assert.Equal(t, "prn:gopher", ByPrincipal(r))
+	assert.Equal(t, "GET /rooms/:id", ByRoute(r))
+	assert.Equal(t, "key:abc", ByAPIKey("X-Api-Key")(r))
+	assert.Equal(t, "GET /rooms/:id|10.0.0.1", Compose(ByRoute, ByIP)(r))
+	anonymous := &Request{Context: context.Background(), Addr: "10.0.0.1:52000", Metadata: http.Header{}.Get}
+	assert.Equal(t, "", ByAPIKey("X-Api-Key")(anonymous))
+	assert.Equal(t, "", Compose(ByAPIKey("X-Api-Key"), ByIP)(anonymous))
+	assert.Equal(t, "10.0.0.1", FirstOf(ByAPIKey("X-Api-Key"), ByIP)(anonymous))
+
+	// requests without a verified principal are limited by IP
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"prn":"forged"}`))
+	for _, ctx := range []context.Context{
+		context.Background(),
+		context.WithValue(ctx, jwt.TokenContextKey, "e30."+claims+".sig"),
+		context.WithValue(ctx, jwt.TokenContextKey, "malformed"),
+		context.WithValue(context.Background(), jwt.TokenContextKey, token),
+	} {
+		assert.Equal(t, "10.0.0.1", ByPrincipal(&Request{Context: ctx, Addr: "10.0.0.1:52000"}))
+	}
+}
+
+func TestNewInvalidLimit(t *testing.T) {
+	for _, limit := range []Limit{
+		PerSecond(0),
+		PerMinute(-1),
+		{Rate: 1},
+		{Rate: 1, Period: -time.Second},
+		{Rate: 1, Period: time.Second, Burst: -1},
+	} {
+		func() {
+			defer func() {
+				assert.Equal(t, true, recover() != nil)
+			}()
+			New(limit)
+			t.Errorf("limit %+v should be invalid", limit)
+		}()
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	l := New(PerMinute(2))
+	mux := router.New()
+	mux.GET("/rooms/:id", router.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		reply.Json(w, r, http.StatusOK, "room")
+	}, l.Middleware()))
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest("GET", "/rooms/1", nil)
+		req.RemoteAddr = "10.0.0.1:52000"
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+		if i == 2 {
+			assert.Equal(t, "30", w.Header().Get("Retry-After"))
+		}
+	}
+	// other clients are not limited
+	req := httptest.NewRequest("GET", "/rooms/1", nil)
+	req.RemoteAddr = "10.0.0.2:52000"
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// headerStream captures the header sent by interceptors
+type headerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *headerStream) Context() context.Context       { return s.ctx }
+func (s *headerStream) SetHeader(md metadata.MD) error { s.header = md; return nil }
+
+func TestInterceptors(t *testing.T) {
+	l := New(PerSecond(1), Key(ByAPIKey("x-api-key")))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "abc"))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 52000}})
+	unary := l.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/helloworld.Greeter/SayHello"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "hello", nil }
+	res, err := unary(ctx, nil, info, handler)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hello", res)
+	_, err = unary(ctx, nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	// streams share the limit of the key
+	stream := l.StreamServerInterceptor()
+	ss := &headerStream{ctx: ctx}
+	err = stream(nil, ss, &grpc.StreamServerInfo{FullMethod: "/helloworld.Greeter/Stream"}, func(interface{}, grpc.ServerStream) error { return nil })
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"1"}, ss.header.Get("retry-after"))
+	// calls without api key are not limited
+	_, err = unary(context.Background(), nil, info, handler)
+	assert.Equal(t, nil, err)
+}
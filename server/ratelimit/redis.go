@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+	"golang.org/x/net/context"
+)
+
+// tokenBucket takes a token from the bucket kept in a hash, refilled at rate
+// tokens per period up to burst, and returns {allowed, remaining, retry
+// after} with times in milliseconds, as MemoryStore does
+var tokenBucket = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local period = tonumber(ARGV[3])
+local burst = tonumber(ARGV[4])
+local b = redis.call('HMGET', key, 'tokens', 'last')
+local tokens = tonumber(b[1]) or burst
+local last = tonumber(b[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - last) * rate / period)
+local allowed = 0
+local wait = 0
+if tokens < 1 then
+	wait = math.ceil((1 - tokens) * period / rate)
+else
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HMSET', key, 'tokens', tokens, 'last', now)
+-- full buckets are no different from new ones
+redis.call('PEXPIRE', key, math.ceil((burst - tokens) * period / rate) + 1)
+return {allowed, math.floor(tokens), wait}
+`)
+
+// RedisStore keeps a token bucket per key in Redis, limiting requests
+// across all server instances sharing it
+type RedisStore struct {
+	clt *redis.Client
+	now func() time.Time
+}
+
+// NewRedisStore returns a store on top of the redis client clt
+// Note: Caller is responsible to close the redis client when its done.
+func NewRedisStore(clt *redis.Client) *RedisStore {
+	return &RedisStore{clt: clt, now: time.Now}
+}
+
+// Allow takes a token from the bucket of key, refilled at the limit rate up
+// to its burst
+func (s *RedisStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := s.now().UnixNano() / int64(time.Millisecond)
+	period := int64(limit.Period / time.Millisecond)
+	v, err := tokenBucket.Run(s.clt.WithContext(ctx), []string{key},
+		now, limit.Rate, period, limit.burst()).Result()
+	if err != nil {
+		return Result{}, err
+	}
+	r, ok := v.([]interface{})
+	if !ok || len(r) != 3 {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %v", v)
+	}
+	allowed, _ := r[0].(int64)
+	remaining, _ := r[1].(int64)
+	wait, _ := r[2].(int64)
+	return Result{
+		Allowed:    allowed == 1,
+		Remaining:  int(remaining),
+		RetryAfter: time.Duration(wait) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aukbit/pluto/v6/reply"
+	"github.com/aukbit/pluto/v6/server/router"
+)
+
+// PanicHandlerFunc is called with the value and stack of panics recovered in
+// http handlers and grpc methods, e.g. to report them to an error tracker
+type PanicHandlerFunc func(ctx context.Context, p interface{}, stack []byte)
+
+// recoveryMiddleware Middleware that recovers from panics in handlers,
+// replying 500 Internal Server Error
+func recoveryMiddleware(s *Server) router.Middleware {
+	return func(h router.HandlerFunc) router.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			sw := newStatusWriter(w)
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				if p == http.ErrAbortHandler {
+					// aborts the response on purpose
+					panic(p)
+				}
+				s.recovered(r.Context(), "http", r.Method+" "+router.PatternFromContext(r.Context()), p)
+				if sw.wrote {
+					// too late to reply, the connection is closed by net/http
+					panic(http.ErrAbortHandler)
+				}
+				reply.Json(w, r, http.StatusInternalServerError, &router.Err{
+					Type:    "api_error",
+					Message: http.StatusText(http.StatusInternalServerError),
+				})
+			}()
+			h.ServeHTTP(sw, r)
+		}
+	}
+}
+
+// recoveryUnaryServerInterceptor recovers from panics in grpc methods,
+// failing the call with Internal
+func recoveryUnaryServerInterceptor(s *Server) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (_ interface{}, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				s.recovered(ctx, "grpc", info.FullMethod, p)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// recoveryStreamServerInterceptor recovers from panics in grpc streams,
+// failing the stream with Internal
+func recoveryStreamServerInterceptor(s *Server) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				s.recovered(ss.Context(), "grpc", info.FullMethod, p)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// --- Helper functions
+
+// recovered logs the panic p with its stack through the request logger,
+// counts it and calls the panic handler if any
+func (s *Server) recovered(ctx context.Context, kind, route string, p interface{}) {
+	stack := debug.Stack()
+	l := zerolog.Ctx(ctx)
+	if l.GetLevel() == zerolog.Disabled {
+		// logger middleware or interceptor skipped
+		l = &s.logger
+	}
+	l.Error().Str("panic", fmt.Sprintf("%v", p)).Bytes("stack", stack).
+		Msg(fmt.Sprintf("%s: panic recovered in %s", s.Name(), route))
+	panicsTotal.WithLabelValues(s.Name(), kind).Inc()
+	if s.cfg.PanicHandler != nil {
+		s.cfg.PanicHandler(ctx, p, stack)
+	}
+}
@@ -0,0 +1,77 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/paulormart/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aukbit/pluto/v6/server"
+	"github.com/aukbit/pluto/v6/server/router"
+	pb "github.com/aukbit/pluto/v6/test/proto"
+)
+
+type panicGreeter struct {
+	pb.UnimplementedGreeterServer
+}
+
+// SayHello panics
+func (s *panicGreeter) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	panic("hello panic")
+}
+
+func TestRecovery(t *testing.T) {
+	var panics int32
+	mux := router.New()
+	mux.GET("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("route panic")
+	})
+	s := server.New(
+		server.Name("recovery"),
+		server.Addr(":8094"),
+		server.Mux(mux),
+		server.GRPCRegister(func(g *grpc.Server) {
+			pb.RegisterGreeterServer(g, &panicGreeter{})
+		}),
+		server.Combined(),
+		server.PanicHandler(func(ctx context.Context, p interface{}, stack []byte) {
+			atomic.AddInt32(&panics, 1)
+		}),
+	)
+	go s.Run()
+	defer s.Stop()
+	time.Sleep(time.Millisecond * 100)
+
+	// http handlers reply 500
+	for i := 0; i < 2; i++ {
+		r, err := http.Get("http://localhost:8094/panic")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var e router.Err
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		r.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, r.StatusCode)
+		assert.Equal(t, "api_error", e.Type)
+	}
+	// grpc methods fail with Internal
+	conn, err := grpc.Dial("localhost:8094", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "Gopher"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&panics))
+	// and the server keeps serving
+	assert.Equal(t, "SERVING", s.Health().Status.String())
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// statusWriter records the status code and size of the response, still
+// exposing the http.Flusher, http.Hijacker and http.Pusher of the writer
+// it wraps
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	size   int  // bytes written in the response body
+	wrote  bool // the response header has been written
+}
+
+// newStatusWriter wraps w, unless it already is a statusWriter set by an
+// outer middleware, which is then shared
+func newStatusWriter(w http.ResponseWriter) *statusWriter {
+	if sw, ok := w.(*statusWriter); ok {
+		return sw
+	}
+	return &statusWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wrote {
+		w.status = code
+		w.wrote = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+// Flush sends any buffered data to the client, if supported
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wrote = true
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection, e.g. for websockets
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	w.wrote = true
+	w.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+// Push initiates an HTTP/2 server push, if supported
+func (w *statusWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
@@ -28,3 +28,15 @@ func FromContextParam(ctx context.Context, param string) string {
 func WithContextParam(ctx context.Context, param, val string) context.Context {
 	return context.WithValue(ctx, contextKey{param}, val)
 }
+
+// patternKey is the context key for the route pattern matched by the router
+type patternKey struct{}
+
+// PatternFromContext returns the route pattern, e.g. /home/:id, matched by
+// the router for the request in context, or "" if no route matched
+func PatternFromContext(ctx context.Context) string {
+	if v, ok := ctx.Value(patternKey{}).(string); ok {
+		return v
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package router
+
+import "strings"
+
+// Group registers routes under a common path prefix, wrapped with the
+// middlewares of the group, e.g. an /admin subtree requiring authentication.
+//
+// Middlewares run in a fixed order: the server middlewares first, then the
+// middlewares of the outer groups and then those of the inner groups, before
+// the handler. As with Wrap, the last middleware of a group runs first.
+type Group struct {
+	router      *Router
+	prefix      string
+	middlewares []Middleware // inner groups first, as applied by Wrap
+}
+
+// Group returns a group of routes with the path prefix, wrapped with mids
+func (r *Router) Group(prefix string, mids ...Middleware) *Group {
+	return &Group{
+		router:      r,
+		prefix:      cleanPrefix(prefix),
+		middlewares: mids,
+	}
+}
+
+// Group returns a group of routes nested in g, with the path prefix appended
+// to the prefix of g and mids running after the middlewares of g
+func (g *Group) Group(prefix string, mids ...Middleware) *Group {
+	return &Group{
+		router:      g.router,
+		prefix:      g.prefix + cleanPrefix(prefix),
+		middlewares: append(append([]Middleware{}, mids...), g.middlewares...),
+	}
+}
+
+// Handle takes a method, pattern relative to the group prefix, and http
+// handler for a route.
+func (g *Group) Handle(method, path string, handler Handler) {
+	g.router.Handle(method, joinPath(g.prefix, path), Wrap(handler.ServeHTTP, g.middlewares...))
+}
+
+// HandleFunc registers the handler function for the given pattern.
+func (g *Group) HandleFunc(method, path string, handlerFn HandlerFunc) {
+	g.Handle(method, path, handlerFn)
+}
+
+// GET is a shortcut for Handle with method "GET"
+func (g *Group) GET(path string, handlerFn HandlerFunc) {
+	g.HandleFunc("GET", path, handlerFn)
+}
+
+// POST is a shortcut for Handle with method "POST"
+func (g *Group) POST(path string, handlerFn HandlerFunc) {
+	g.HandleFunc("POST", path, handlerFn)
+}
+
+// PUT is a shortcut for Handle with method "PUT"
+func (g *Group) PUT(path string, handlerFn HandlerFunc) {
+	g.HandleFunc("PUT", path, handlerFn)
+}
+
+// DELETE is a shortcut for Handle with method "DELETE"
+func (g *Group) DELETE(path string, handlerFn HandlerFunc) {
+	g.HandleFunc("DELETE", path, handlerFn)
+}
+
+// PATCH is a shortcut for Handle with method "PATCH"
+func (g *Group) PATCH(path string, handlerFn HandlerFunc) {
+	g.HandleFunc("PATCH", path, handlerFn)
+}
+
+// HEAD is a shortcut for Handle with method "HEAD"
+func (g *Group) HEAD(path string, handlerFn HandlerFunc) {
+	g.HandleFunc("HEAD", path, handlerFn)
+}
+
+// OPTIONS is a shortcut for Handle with method "OPTIONS"
+func (g *Group) OPTIONS(path string, handlerFn HandlerFunc) {
+	g.HandleFunc("OPTIONS", path, handlerFn)
+}
+
+// Describe attaches metadata to the route of method and path, relative to
+// the group prefix
+func (g *Group) Describe(method, path string, m Metadata) {
+	g.router.Describe(method, joinPath(g.prefix, path), m)
+}
+
+// Mount registers the routes of sub under the path prefix of the group
+// joined with prefix, wrapped with the middlewares of the group
+func (g *Group) Mount(prefix string, sub *Router) {
+	g.Group(prefix).mount(sub)
+}
+
+// Mount registers the routes of sub under the path prefix, e.g. a router of
+// another package. Routes added to sub after Mount are not served by r.
+func (r *Router) Mount(prefix string, sub *Router) {
+	r.Group(prefix).mount(sub)
+}
+
+// mount registers the routes of sub in the group
+func (g *Group) mount(sub *Router) {
+	for _, data := range sub.routes {
+		for m, h := range data.methods {
+			g.Handle(m, data.pattern, h)
+			if meta, ok := data.meta[m]; ok {
+				g.router.describe(m, joinPath(g.prefix, data.path), meta)
+			}
+		}
+	}
+}
+
+// cleanPrefix returns prefix starting with / and without a trailing /
+func cleanPrefix(prefix string) string {
+	prefix = strings.TrimRight(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	if prefix[0] != '/' {
+		panic("Prefix must start with /")
+	}
+	return prefix
+}
+
+// joinPath returns path under prefix, e.g. /admin and / -> /admin
+func joinPath(prefix, path string) string {
+	if path == "" || path[0] != '/' {
+		panic("Path must start with /")
+	}
+	if path == "/" && prefix != "" {
+		return prefix
+	}
+	return prefix + path
+}
@@ -0,0 +1,116 @@
+package router_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/paulormart/assert"
+)
+
+// trail returns a middleware appending name to the X-Trail header
+func trail(name string) router.Middleware {
+	return func(h router.HandlerFunc) router.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trail", name)
+			h.ServeHTTP(w, r)
+		}
+	}
+}
+
+func requireToken(h router.HandlerFunc) router.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer admin" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	r := router.New()
+	r.GET("/public", Index)
+	admin := r.Group("/admin", requireToken)
+	admin.GET("/", Index)
+	admin.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, router.FromContext(r.Context(), "id")+" "+router.PatternFromContext(r.Context()))
+	})
+
+	var tests = []struct {
+		Path   string
+		Token  string
+		Status int
+		Body   string
+	}{
+		{Path: "/public", Status: http.StatusOK, Body: "Hello World"},
+		{Path: "/admin", Status: http.StatusUnauthorized},
+		{Path: "/admin", Token: "Bearer admin", Status: http.StatusOK, Body: "Hello World"},
+		{Path: "/admin/users/123", Status: http.StatusUnauthorized},
+		{Path: "/admin/users/123", Token: "Bearer admin", Status: http.StatusOK, Body: "123 /admin/users/:id"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.Path, nil)
+		if test.Token != "" {
+			req.Header.Set("Authorization", test.Token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code)
+		assert.Equal(t, test.Body, w.Body.String())
+	}
+}
+
+func TestGroupMiddlewareOrder(t *testing.T) {
+	r := router.New()
+	api := r.Group("/api", trail("api2"), trail("api1"))
+	v1 := api.Group("/v1", trail("v1"))
+	v1.GET("/rooms", Index)
+	// as the server does with its own middlewares once routes are set
+	r.WrapperMiddleware(trail("server"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/rooms", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "server,api1,api2,v1", strings.Join(w.Header()["X-Trail"], ","))
+}
+
+func TestMount(t *testing.T) {
+	users := router.New()
+	users.GET("/", Index)
+	users.GET("/:id", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, router.FromContext(r.Context(), "id"))
+	})
+	users.POST("/:id", Index)
+
+	r := router.New()
+	r.Mount("/users", users)
+	r.Group("/admin", requireToken).Mount("/users", users)
+
+	var tests = []struct {
+		Method string
+		Path   string
+		Token  string
+		Status int
+		Body   string
+	}{
+		{Method: "GET", Path: "/users", Status: http.StatusOK, Body: "Hello World"},
+		{Method: "GET", Path: "/users/123", Status: http.StatusOK, Body: "123"},
+		{Method: "POST", Path: "/users/123", Status: http.StatusOK, Body: "Hello World"},
+		{Method: "GET", Path: "/admin/users/123", Status: http.StatusUnauthorized},
+		{Method: "GET", Path: "/admin/users/123", Token: "Bearer admin", Status: http.StatusOK, Body: "123"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.Method, test.Path, nil)
+		if test.Token != "" {
+			req.Header.Set("Authorization", test.Token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code)
+		assert.Equal(t, test.Body, w.Body.String())
+	}
+}
@@ -8,6 +8,9 @@ type Mux interface {
 	POST(string, HandlerFunc)
 	PUT(string, HandlerFunc)
 	DELETE(string, HandlerFunc)
+	PATCH(string, HandlerFunc)
+	HEAD(string, HandlerFunc)
+	OPTIONS(string, HandlerFunc)
 	HandleFunc(string, string, HandlerFunc)
 	ServeHTTP(http.ResponseWriter, *http.Request)
 	WrapperMiddleware(...Middleware)
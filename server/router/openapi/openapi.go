@@ -0,0 +1,232 @@
+// Package openapi generates OpenAPI 3 documents of the routes served by a
+// router, from the metadata described for them and, for requests and
+// responses of proto message types, from the message descriptors, e.g.
+//
+//	r.GET("/users/:id", getUser)
+//	r.Describe("GET", "/users/:id", router.Metadata{
+//		Summary:  "Get a user",
+//		Request:  &pb.UserRequest{},
+//		Response: &pb.User{},
+//	})
+//	doc := openapi.New(r, openapi.Info{Title: "users", Version: "1.0.0"})
+package openapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aukbit/pluto/v6/reply"
+	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/golang/protobuf/descriptor"
+	"github.com/golang/protobuf/proto"
+	pb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// Version of the OpenAPI specification of the documents generated
+const Version = "3.0.3"
+
+// DefaultVersion is the version of the API documented if none is given
+const DefaultVersion = "0.0.0"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components *Components         `json:"components,omitempty"`
+}
+
+// Info about the API documented
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations of a path by lower case method
+type PathItem map[string]*Operation
+
+// Operation of a path
+type Operation struct {
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+}
+
+// Parameter of an operation, in path or query
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody of an operation
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response of an operation
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType of a request or response body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas of the messages referenced by operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema of a value
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// methods that OpenAPI documents
+var methods = map[string]bool{
+	"GET": true, "PUT": true, "POST": true, "DELETE": true,
+	"OPTIONS": true, "HEAD": true, "PATCH": true, "TRACE": true,
+}
+
+// New returns the OpenAPI document of the routes of r. Routes whose paths
+// only differ in constraints of params are documented by the first one
+// registered.
+func New(r *router.Router, info Info) *Document {
+	if info.Version == "" {
+		info.Version = DefaultVersion
+	}
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+	}
+	g := &generator{schemas: make(map[string]*Schema)}
+	for _, route := range r.Routes() {
+		if !methods[route.Method] {
+			continue
+		}
+		p := path(route)
+		if doc.Paths[p] == nil {
+			doc.Paths[p] = PathItem{}
+		}
+		m := strings.ToLower(route.Method)
+		if _, ok := doc.Paths[p][m]; ok {
+			continue
+		}
+		doc.Paths[p][m] = g.operation(route)
+	}
+	if len(g.schemas) > 0 {
+		doc.Components = &Components{Schemas: g.schemas}
+	}
+	return doc
+}
+
+// Handler returns a handler replying with the OpenAPI document of the
+// routes of r
+func Handler(r *router.Router, info Info) router.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		reply.Json(w, req, http.StatusOK, New(r, info))
+	}
+}
+
+// path returns the path of route as templated by OpenAPI, e.g.
+// /users/:id{[0-9]+} -> /users/{id}
+func path(route router.Route) string {
+	segments := strings.Split(route.Path, "/")
+	i := 0
+	for j, s := range segments {
+		if (strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*")) && i < len(route.Params) {
+			segments[j] = "{" + route.Params[i].Name + "}"
+			i++
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operation returns the operation of route. Params are path parameters,
+// typed as the fields of the same name of a proto request. Other fields are
+// query parameters of GET, HEAD and DELETE operations, or the request body
+// of the others.
+func (g *generator) operation(route router.Route) *Operation {
+	meta := route.Metadata
+	op := &Operation{
+		Summary:     meta.Summary,
+		Description: meta.Description,
+		Tags:        meta.Tags,
+		Deprecated:  meta.Deprecated,
+		Responses:   map[string]*Response{"200": {Description: "OK"}},
+	}
+	in, md := message(meta.Request)
+	bound := make(map[string]bool)
+	for _, p := range route.Params {
+		param := &Parameter{Name: p.Name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+		if f := field(md, p.Name); f != nil && f.GetType() != typeMessage {
+			param.Schema = g.value(f)
+			bound[f.GetName()] = true
+		}
+		if p.Constraint != "" && param.Schema.Type == "string" {
+			param.Schema.Pattern = "^(?:" + p.Constraint + ")$"
+		}
+		if p.CatchAll {
+			param.Description = "rest of the path, / included"
+		}
+		op.Parameters = append(op.Parameters, param)
+	}
+	if in != "" {
+		switch route.Method {
+		case "GET", "HEAD", "DELETE":
+			for _, f := range md.GetField() {
+				if bound[f.GetName()] || f.GetType() == typeMessage {
+					continue
+				}
+				op.Parameters = append(op.Parameters, &Parameter{
+					Name:   jsonName(f),
+					In:     "query",
+					Schema: g.field(md, f),
+				})
+			}
+		default:
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]*MediaType{"application/json": {Schema: g.ref(in)}},
+			}
+		}
+	}
+	if out, _ := message(meta.Response); out != "" {
+		op.Responses["200"].Content = map[string]*MediaType{"application/json": {Schema: g.ref(out)}}
+	}
+	return op
+}
+
+// message returns the name and the descriptor of v, if a proto message
+func message(v interface{}) (string, *pb.DescriptorProto) {
+	m, ok := v.(descriptor.Message)
+	if !ok {
+		return "", nil
+	}
+	name := proto.MessageName(m)
+	if name == "" {
+		return "", nil
+	}
+	_, md := descriptor.ForMessage(m)
+	return name, md
+}
@@ -0,0 +1,104 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/aukbit/pluto/v6/server/router/openapi"
+	pb "github.com/aukbit/pluto/v6/test/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/paulormart/assert"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+)
+
+func ok(w http.ResponseWriter, r *http.Request) {}
+
+func TestNew(t *testing.T) {
+	r := router.New()
+	r.GET("/hello/:name", ok)
+	r.POST("/hello/:name", ok)
+	r.GET("/users/:id{uint}", ok)
+	r.GET("/files/*path", ok)
+	r.GET("/archive/:year?", ok)
+	r.Describe("GET", "/hello/:name", router.Metadata{
+		Summary:  "Say hello",
+		Tags:     []string{"greeter"},
+		Request:  &pb.HelloRequest{},
+		Response: &pb.HelloReply{},
+	})
+	r.Describe("POST", "/hello/:name", router.Metadata{
+		Request:  &pb.HelloRequest{},
+		Response: &pb.HelloReply{},
+	})
+	doc := openapi.New(r, openapi.Info{Title: "test"})
+
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, openapi.DefaultVersion, doc.Info.Version)
+	assert.Equal(t, 5, len(doc.Paths))
+
+	get := doc.Paths["/hello/{name}"]["get"]
+	assert.Equal(t, "Say hello", get.Summary)
+	assert.Equal(t, []string{"greeter"}, get.Tags)
+	assert.Equal(t, 1, len(get.Parameters))
+	assert.Equal(t, &openapi.Parameter{Name: "name", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}, get.Parameters[0])
+	assert.Equal(t, (*openapi.RequestBody)(nil), get.RequestBody)
+	assert.Equal(t, "#/components/schemas/helloworld.HelloReply", get.Responses["200"].Content["application/json"].Schema.Ref)
+
+	post := doc.Paths["/hello/{name}"]["post"]
+	assert.Equal(t, "#/components/schemas/helloworld.HelloRequest", post.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Equal(t, &openapi.Schema{Type: "string"}, doc.Components.Schemas["helloworld.HelloRequest"].Properties["name"])
+	assert.Equal(t, &openapi.Schema{Type: "string"}, doc.Components.Schemas["helloworld.HelloReply"].Properties["message"])
+
+	id := doc.Paths["/users/{id}"]["get"].Parameters[0]
+	assert.Equal(t, "^(?:[0-9]+)$", id.Schema.Pattern)
+	assert.Equal(t, "path", doc.Paths["/files/{path}"]["get"].Parameters[0].Name)
+	assert.Equal(t, 0, len(doc.Paths["/archive"]["get"].Parameters))
+	assert.Equal(t, "year", doc.Paths["/archive/{year}"]["get"].Parameters[0].Name)
+	assert.Equal(t, &openapi.Response{Description: "OK"}, doc.Paths["/users/{id}"]["get"].Responses["200"])
+}
+
+func TestSchemas(t *testing.T) {
+	r := router.New()
+	r.GET("/fields/:number", ok)
+	r.PUT("/messages", ok)
+	r.PUT("/resources", ok)
+	r.Describe("GET", "/fields/:number", router.Metadata{Request: &descriptor.FieldDescriptorProto{}})
+	r.Describe("PUT", "/messages", router.Metadata{Request: &descriptor.DescriptorProto{}})
+	r.Describe("PUT", "/resources", router.Metadata{Request: &monitoredres.MonitoredResource{}})
+	doc := openapi.New(r, openapi.Info{Title: "test", Version: "1.0.0"})
+
+	// fields bound to params are typed, the others are query parameters
+	params := doc.Paths["/fields/{number}"]["get"].Parameters
+	assert.Equal(t, &openapi.Schema{Type: "integer", Format: "int32"}, params[0].Schema)
+	var query = make(map[string]*openapi.Schema)
+	for _, p := range params[1:] {
+		assert.Equal(t, "query", p.In)
+		query[p.Name] = p.Schema
+	}
+	assert.Equal(t, &openapi.Schema{Type: "string"}, query["typeName"])
+	assert.Equal(t, "LABEL_OPTIONAL", query["label"].Enum[0])
+	assert.Equal(t, (*openapi.Schema)(nil), query["options"])
+
+	// nested and recursive messages are referenced
+	schemas := doc.Components.Schemas
+	message := schemas["google.protobuf.DescriptorProto"]
+	assert.Equal(t, "array", message.Properties["nestedType"].Type)
+	assert.Equal(t, "#/components/schemas/google.protobuf.DescriptorProto", message.Properties["nestedType"].Items.Ref)
+	assert.Equal(t, "#/components/schemas/google.protobuf.FieldDescriptorProto", message.Properties["field"].Items.Ref)
+	assert.Equal(t, "object", schemas["google.protobuf.FieldDescriptorProto"].Type)
+
+	// maps are objects of their values
+	labels := schemas["google.api.MonitoredResource"].Properties["labels"]
+	assert.Equal(t, &openapi.Schema{Type: "object", AdditionalProperties: &openapi.Schema{Type: "string"}}, labels)
+
+	// the document is valid json
+	w := httptest.NewRecorder()
+	openapi.Handler(r, openapi.Info{Title: "test"})(w, httptest.NewRequest("GET", "/_routes/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var v map[string]interface{}
+	assert.Equal(t, nil, json.Unmarshal(w.Body.Bytes(), &v))
+	assert.Equal(t, "3.0.3", v["openapi"])
+}
@@ -0,0 +1,172 @@
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/descriptor"
+	"github.com/golang/protobuf/proto"
+	pb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+const typeMessage = pb.FieldDescriptorProto_TYPE_MESSAGE
+
+// wellKnown are the schemas of the well known types, as mapped to json
+var wellKnown = map[string]Schema{
+	"google.protobuf.Any":         {Type: "object"},
+	"google.protobuf.Duration":    {Type: "string"},
+	"google.protobuf.Empty":       {Type: "object"},
+	"google.protobuf.FieldMask":   {Type: "string"},
+	"google.protobuf.ListValue":   {Type: "array", Items: &Schema{}},
+	"google.protobuf.Struct":      {Type: "object"},
+	"google.protobuf.Timestamp":   {Type: "string", Format: "date-time"},
+	"google.protobuf.Value":       {},
+	"google.protobuf.BoolValue":   {Type: "boolean"},
+	"google.protobuf.BytesValue":  {Type: "string", Format: "byte"},
+	"google.protobuf.DoubleValue": {Type: "number", Format: "double"},
+	"google.protobuf.FloatValue":  {Type: "number", Format: "float"},
+	"google.protobuf.Int32Value":  {Type: "integer", Format: "int32"},
+	"google.protobuf.Int64Value":  {Type: "string", Format: "int64"},
+	"google.protobuf.StringValue": {Type: "string"},
+	"google.protobuf.UInt32Value": {Type: "integer", Format: "int64"},
+	"google.protobuf.UInt64Value": {Type: "string", Format: "uint64"},
+}
+
+// generator of schemas, which keeps the schemas of the messages referenced
+type generator struct {
+	schemas map[string]*Schema // by full message name
+}
+
+// ref returns the schema referencing the message of full name, e.g.
+// helloworld.HelloRequest, added to the schemas with the messages of its
+// fields if not yet
+func (g *generator) ref(name string) *Schema {
+	if s, ok := wellKnown[name]; ok {
+		return &s
+	}
+	if _, ok := g.schemas[name]; !ok {
+		// added before the fields, which may reference the message again
+		g.schemas[name] = &Schema{Type: "object"}
+		if t := proto.MessageType(name); t != nil {
+			if m, ok := reflect.New(t.Elem()).Interface().(descriptor.Message); ok {
+				_, md := descriptor.ForMessage(m)
+				*g.schemas[name] = *g.message(md)
+			}
+		}
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// message returns the schema of the message md
+func (g *generator) message(md *pb.DescriptorProto) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, f := range md.GetField() {
+		s.Properties[jsonName(f)] = g.field(md, f)
+	}
+	return s
+}
+
+// field returns the schema of the field f of the message md
+func (g *generator) field(md *pb.DescriptorProto, f *pb.FieldDescriptorProto) *Schema {
+	if entry := mapEntry(md, f); entry != nil {
+		return &Schema{Type: "object", AdditionalProperties: g.value(field(entry, "value"))}
+	}
+	if f.GetLabel() == pb.FieldDescriptorProto_LABEL_REPEATED {
+		return &Schema{Type: "array", Items: g.value(f)}
+	}
+	return g.value(f)
+}
+
+// value returns the schema of a value of the field f, as mapped to json
+func (g *generator) value(f *pb.FieldDescriptorProto) *Schema {
+	switch f.GetType() {
+	case pb.FieldDescriptorProto_TYPE_MESSAGE, pb.FieldDescriptorProto_TYPE_GROUP:
+		return g.ref(strings.TrimPrefix(f.GetTypeName(), "."))
+	case pb.FieldDescriptorProto_TYPE_ENUM:
+		return enum(strings.TrimPrefix(f.GetTypeName(), "."))
+	case pb.FieldDescriptorProto_TYPE_BOOL:
+		return &Schema{Type: "boolean"}
+	case pb.FieldDescriptorProto_TYPE_STRING:
+		return &Schema{Type: "string"}
+	case pb.FieldDescriptorProto_TYPE_BYTES:
+		return &Schema{Type: "string", Format: "byte"}
+	case pb.FieldDescriptorProto_TYPE_DOUBLE:
+		return &Schema{Type: "number", Format: "double"}
+	case pb.FieldDescriptorProto_TYPE_FLOAT:
+		return &Schema{Type: "number", Format: "float"}
+	case pb.FieldDescriptorProto_TYPE_INT32, pb.FieldDescriptorProto_TYPE_SINT32, pb.FieldDescriptorProto_TYPE_SFIXED32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case pb.FieldDescriptorProto_TYPE_UINT32, pb.FieldDescriptorProto_TYPE_FIXED32:
+		return &Schema{Type: "integer", Format: "int64"}
+	case pb.FieldDescriptorProto_TYPE_UINT64, pb.FieldDescriptorProto_TYPE_FIXED64:
+		// 64 bit integers are json strings
+		return &Schema{Type: "string", Format: "uint64"}
+	default:
+		return &Schema{Type: "string", Format: "int64"}
+	}
+}
+
+// enum returns the schema of the enum of full name, with the names of its
+// values in order
+func enum(name string) *Schema {
+	values := proto.EnumValueMap(name)
+	// nested enums are registered as Outer_Inner
+	for i := strings.LastIndex(name, "."); values == nil && i != -1; i = strings.LastIndex(name, ".") {
+		name = name[:i] + "_" + name[i+1:]
+		values = proto.EnumValueMap(name)
+	}
+	s := &Schema{Type: "string"}
+	for v := range values {
+		s.Enum = append(s.Enum, v)
+	}
+	sort.Slice(s.Enum, func(i, j int) bool {
+		return values[s.Enum[i]] < values[s.Enum[j]]
+	})
+	return s
+}
+
+// mapEntry returns the entry message of the field f of md, if a map
+func mapEntry(md *pb.DescriptorProto, f *pb.FieldDescriptorProto) *pb.DescriptorProto {
+	if f.GetType() != typeMessage || f.GetLabel() != pb.FieldDescriptorProto_LABEL_REPEATED {
+		return nil
+	}
+	for _, nt := range md.GetNestedType() {
+		if nt.GetOptions().GetMapEntry() && strings.HasSuffix(f.GetTypeName(), "."+md.GetName()+"."+nt.GetName()) {
+			return nt
+		}
+	}
+	return nil
+}
+
+// field returns the field of md of the proto or json name, if any
+func field(md *pb.DescriptorProto, name string) *pb.FieldDescriptorProto {
+	for _, f := range md.GetField() {
+		if f.GetName() == name || jsonName(f) == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// jsonName returns the json name of the field f, e.g. first_name -> firstName
+func jsonName(f *pb.FieldDescriptorProto) string {
+	if f.GetJsonName() != "" {
+		return f.GetJsonName()
+	}
+	var b strings.Builder
+	upper := false
+	for _, c := range f.GetName() {
+		switch {
+		case c == '_':
+			upper = true
+		case upper && 'a' <= c && c <= 'z':
+			b.WriteRune(c - 'a' + 'A')
+			upper = false
+		default:
+			b.WriteRune(c)
+			upper = false
+		}
+	}
+	return b.String()
+}
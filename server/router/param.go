@@ -0,0 +1,125 @@
+package router
+
+import (
+	"regexp"
+	"strings"
+)
+
+// segmentKind is the kind of a path segment in a route pattern, in order of
+// matching precedence
+type segmentKind int
+
+const (
+	staticSegment      segmentKind = iota // e.g. /home
+	constrainedSegment                    // e.g. /:id{[0-9]+} or /:id{uuid}
+	paramSegment                          // e.g. /:id
+	catchAllSegment                       // e.g. /*path, matches the rest of the path
+)
+
+// ParamTypes are the constraints that can be named in route patterns,
+// e.g. /rooms/:id{int}, instead of a regular expression
+var ParamTypes = map[string]string{
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"alpha": `[A-Za-z]+`,
+	"alnum": `[A-Za-z0-9]+`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+// segment of a route pattern
+type segment struct {
+	kind       segmentKind
+	value      string         // static text or param name
+	constraint string         // regular expression of the param, if constrained
+	re         *regexp.Regexp // constraint of the param, anchored
+}
+
+// parseSegments returns the segments of the route pattern path, e.g.
+// /files/:id{[0-9]+}/*path
+func parseSegments(path string) []segment {
+	if path == "/" {
+		return nil
+	}
+	parts := strings.Split(path[1:], "/")
+	segments := make([]segment, len(parts))
+	for i, s := range parts {
+		switch {
+		case strings.HasPrefix(s, "*"):
+			if i != len(parts)-1 {
+				panic("Catch-all parameter must be the last segment in path " + path)
+			}
+			segments[i] = segment{kind: catchAllSegment, value: s[1:]}
+		case strings.HasPrefix(s, ":"):
+			name, constraint := splitConstraint(s[1:], path)
+			if constraint == "" {
+				segments[i] = segment{kind: paramSegment, value: name}
+				continue
+			}
+			segments[i] = segment{
+				kind:       constrainedSegment,
+				value:      name,
+				constraint: constraint,
+				re:         regexp.MustCompile("^(?:" + constraint + ")$"),
+			}
+		default:
+			segments[i] = segment{kind: staticSegment, value: s}
+		}
+	}
+	return segments
+}
+
+// splitConstraint returns the name and the regular expression of the param
+// s, e.g. id{[0-9]+} or id{int}
+func splitConstraint(s, path string) (name, constraint string) {
+	i := strings.Index(s, "{")
+	if i == -1 {
+		return s, ""
+	}
+	if !strings.HasSuffix(s, "}") || i == len(s)-2 {
+		panic("Invalid constraint of parameter " + s + " in path " + path)
+	}
+	name, constraint = s[:i], s[i+1:len(s)-1]
+	if re, ok := ParamTypes[constraint]; ok {
+		return name, re
+	}
+	return name, constraint
+}
+
+// expandOptional returns the paths of a pattern with optional trailing
+// segments, e.g. /archive/:year?/:month? -> /archive, /archive/:year,
+// /archive/:year/:month
+func expandOptional(path string) []string {
+	if !strings.Contains(path, "?") {
+		return []string{path}
+	}
+	parts := strings.Split(path[1:], "/")
+	first := len(parts)
+	for first > 0 && strings.HasSuffix(parts[first-1], "?") {
+		first--
+	}
+	for _, s := range parts[:first] {
+		if strings.HasSuffix(s, "?") {
+			panic("Optional segments must be trailing in path " + path)
+		}
+	}
+	paths := make([]string, 0, len(parts)-first+1)
+	for n := first; n <= len(parts); n++ {
+		p := make([]string, n)
+		for i := range p {
+			p[i] = strings.TrimSuffix(parts[i], "?")
+		}
+		paths = append(paths, "/"+strings.Join(p, "/"))
+	}
+	return paths
+}
+
+// params returns the names of the params of the route pattern path
+func params(path string) []string {
+	names := []string{}
+	for _, s := range parseSegments(path) {
+		if s.kind != staticSegment {
+			names = append(names, s.value)
+		}
+	}
+	return names
+}
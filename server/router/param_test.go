@@ -0,0 +1,117 @@
+package router_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/paulormart/assert"
+)
+
+// route returns a handler writing the pattern matched and the values of
+// params
+func route(params ...string) router.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		values := []string{router.PatternFromContext(r.Context())}
+		for _, p := range params {
+			values = append(values, p+"="+router.FromContextParam(r.Context(), p))
+		}
+		io.WriteString(w, strings.Join(values, " "))
+	}
+}
+
+func TestParams(t *testing.T) {
+	r := router.New()
+	r.GET("/files/*path", route("path"))
+	r.GET("/files/readme", route())
+	r.GET("/users/:id{[0-9]+}", route("id"))
+	r.GET("/users/:name", route("name"))
+	r.GET("/users/me", route())
+	r.GET("/rooms/:id{uuid}/*rest", route("id", "rest"))
+	r.GET("/rooms/:name/:floor{uint}", route("name", "floor"))
+	r.GET("/archive/:year{int}?/:month?", route("year", "month"))
+
+	var tests = []struct {
+		Path   string
+		Status int
+		Body   string
+	}{
+		{Path: "/files/readme", Status: http.StatusOK, Body: "/files/readme"},
+		{Path: "/files/docs/guide.md", Status: http.StatusOK, Body: "/files/*path path=docs/guide.md"},
+		{Path: "/files", Status: http.StatusOK, Body: "/files/*path path="},
+		{Path: "/users/me", Status: http.StatusOK, Body: "/users/me"},
+		{Path: "/users/123", Status: http.StatusOK, Body: "/users/:id{[0-9]+} id=123"},
+		{Path: "/users/gopher", Status: http.StatusOK, Body: "/users/:name name=gopher"},
+		{Path: "/users/123/rooms", Status: http.StatusNotFound},
+		{Path: "/rooms/9f0c2a5e-1b7d-4c3a-8e2f-6a1b2c3d4e5f/a/b", Status: http.StatusOK, Body: "/rooms/:id{uuid}/*rest id=9f0c2a5e-1b7d-4c3a-8e2f-6a1b2c3d4e5f rest=a/b"},
+		{Path: "/rooms/hall/2", Status: http.StatusOK, Body: "/rooms/:name/:floor{uint} name=hall floor=2"},
+		{Path: "/rooms/hall/two", Status: http.StatusNotFound},
+		{Path: "/archive", Status: http.StatusOK, Body: "/archive/:year{int}?/:month? year= month="},
+		{Path: "/archive/2019", Status: http.StatusOK, Body: "/archive/:year{int}?/:month? year=2019 month="},
+		{Path: "/archive/2019/10", Status: http.StatusOK, Body: "/archive/:year{int}?/:month? year=2019 month=10"},
+		{Path: "/archive/last", Status: http.StatusNotFound},
+	}
+	for _, test := range tests {
+		// precedence is the same on every request
+		for i := 0; i < 10; i++ {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", test.Path, nil))
+			assert.Equal(t, test.Status, w.Code)
+			if test.Status == http.StatusOK {
+				assert.Equal(t, test.Body, w.Body.String())
+			}
+		}
+	}
+}
+
+func TestParamsPrecedence(t *testing.T) {
+	r := router.New()
+	r.GET("/*all", route("all"))
+	r.GET("/a/b/*rest", route("rest"))
+	r.GET("/a/b", route())
+	r.GET("/:a/:b", route("a", "b"))
+	r.GET("/:a/b", route("a"))
+	r.GET("/a/:b", route("b"))
+	r.GET("/:a{[a-z]}/:b", route("a", "b"))
+
+	var tests = []struct {
+		Path string
+		Body string
+	}{
+		// static precede params from the first segment on
+		{Path: "/a/b", Body: "/a/b"},
+		{Path: "/a/c", Body: "/a/:b b=c"},
+		{Path: "/a/b/c", Body: "/a/b/*rest rest=c"},
+		{Path: "/x/b", Body: "/:a{[a-z]}/:b a=x b=b"},
+		{Path: "/xy/b", Body: "/:a/b a=xy"},
+		{Path: "/xy/z", Body: "/:a/:b a=xy b=z"},
+		{Path: "/xy/z/w", Body: "/*all all=xy/z/w"},
+	}
+	for _, test := range tests {
+		for i := 0; i < 10; i++ {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", test.Path, nil))
+			assert.Equal(t, test.Body, w.Body.String())
+		}
+	}
+}
+
+func TestParamsInvalid(t *testing.T) {
+	for _, path := range []string{
+		"/files/*path/more",
+		"/archive/:year?/:month",
+		"/users/:id{",
+		"/users/:id{[0-9+}",
+	} {
+		func() {
+			defer func() {
+				assert.Equal(t, true, recover() != nil)
+			}()
+			router.New().GET(path, route())
+			t.Errorf("path %s should not be valid", path)
+		}()
+	}
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"sort"
+	"strings"
+)
+
+// Metadata describes a route in listings and OpenAPI documents
+type Metadata struct {
+	Summary     string   `json:"summary,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+	// Request and Response are values of the types read from the request
+	// and replied, e.g. proto messages, which OpenAPI documents describe
+	// from their descriptors
+	Request  interface{} `json:"-"`
+	Response interface{} `json:"-"`
+}
+
+// Param of a route
+type Param struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint,omitempty"` // regular expression matched by the value, if constrained
+	CatchAll   bool   `json:"catch_all,omitempty"`  // the value is the rest of the path
+}
+
+// Route served by a router
+type Route struct {
+	Method   string   `json:"method"`
+	Pattern  string   `json:"pattern"` // as registered, e.g. /archive/:year?
+	Path     string   `json:"path"`    // optional segments of the pattern resolved, e.g. /archive/:year
+	Params   []Param  `json:"params"`
+	Metadata Metadata `json:"metadata"`
+}
+
+// Routes returns the routes registered, in order of registration and of
+// method. Patterns with optional segments have a route for each path.
+func (r *Router) Routes() []Route {
+	var routes []Route
+	for _, data := range r.routes {
+		methods := make([]string, 0, len(data.methods))
+		for m := range data.methods {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+		var params []Param
+		for _, s := range parseSegments(data.path) {
+			switch s.kind {
+			case constrainedSegment:
+				params = append(params, Param{Name: s.value, Constraint: s.constraint})
+			case paramSegment:
+				params = append(params, Param{Name: s.value})
+			case catchAllSegment:
+				params = append(params, Param{Name: s.value, CatchAll: true})
+			}
+		}
+		for _, m := range methods {
+			routes = append(routes, Route{
+				Method:   m,
+				Pattern:  data.pattern,
+				Path:     data.path,
+				Params:   params,
+				Metadata: data.meta[m],
+			})
+		}
+	}
+	return routes
+}
+
+// Describe attaches metadata to the route of method and path, as given to
+// Handle, replacing any described before.
+func (r *Router) Describe(method, path string, m Metadata) {
+	for _, p := range expandOptional(path) {
+		r.describe(method, p, m)
+	}
+}
+
+// describe attaches metadata to the route of method and path, without
+// optional segments
+func (r *Router) describe(method, path string, m Metadata) {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	for _, data := range r.routes {
+		if data.path != path {
+			continue
+		}
+		if _, ok := data.methods[method]; ok {
+			data.meta[method] = m
+			return
+		}
+	}
+	panic("Route " + method + " " + path + " is not registered")
+}
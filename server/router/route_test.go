@@ -0,0 +1,81 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/paulormart/assert"
+)
+
+func TestRoutes(t *testing.T) {
+	r := router.New()
+	r.GET("/", Index)
+	r.POST("/users", Index)
+	r.GET("/users/:id{uint}", Index)
+	r.DELETE("/users/:id{uint}", Index)
+	r.GET("/files/*path", Index)
+	r.GET("/archive/:year?", Index)
+	r.Describe("GET", "/users/:id{uint}", router.Metadata{Summary: "Get a user", Tags: []string{"users"}})
+	r.Describe("GET", "/archive/:year?", router.Metadata{Summary: "Archive"})
+
+	routes := r.Routes()
+	assert.Equal(t, 7, len(routes))
+	var tests = []struct {
+		Method  string
+		Pattern string
+		Path    string
+		Params  []router.Param
+		Summary string
+	}{
+		{Method: "GET", Pattern: "/", Path: "/"},
+		{Method: "POST", Pattern: "/users", Path: "/users"},
+		{Method: "DELETE", Pattern: "/users/:id{uint}", Path: "/users/:id{uint}", Params: []router.Param{{Name: "id", Constraint: "[0-9]+"}}},
+		{Method: "GET", Pattern: "/users/:id{uint}", Path: "/users/:id{uint}", Params: []router.Param{{Name: "id", Constraint: "[0-9]+"}}, Summary: "Get a user"},
+		{Method: "GET", Pattern: "/files/*path", Path: "/files/*path", Params: []router.Param{{Name: "path", CatchAll: true}}},
+		{Method: "GET", Pattern: "/archive/:year?", Path: "/archive", Summary: "Archive"},
+		{Method: "GET", Pattern: "/archive/:year?", Path: "/archive/:year", Params: []router.Param{{Name: "year"}}, Summary: "Archive"},
+	}
+	for i, test := range tests {
+		assert.Equal(t, test.Method, routes[i].Method)
+		assert.Equal(t, test.Pattern, routes[i].Pattern)
+		assert.Equal(t, test.Path, routes[i].Path)
+		assert.Equal(t, test.Params, routes[i].Params)
+		assert.Equal(t, test.Summary, routes[i].Metadata.Summary)
+	}
+}
+
+func TestRoutesGroup(t *testing.T) {
+	sub := router.New()
+	sub.GET("/items/:id", Index)
+	sub.Describe("GET", "/items/:id", router.Metadata{Summary: "Get an item"})
+
+	r := router.New()
+	api := r.Group("/api")
+	api.POST("/items", Index)
+	api.Describe("POST", "/items", router.Metadata{Summary: "Create an item"})
+	api.Mount("/v1", sub)
+
+	routes := r.Routes()
+	assert.Equal(t, 2, len(routes))
+	assert.Equal(t, "/api/items", routes[0].Path)
+	assert.Equal(t, "Create an item", routes[0].Metadata.Summary)
+	assert.Equal(t, "/api/v1/items/:id", routes[1].Path)
+	assert.Equal(t, "Get an item", routes[1].Metadata.Summary)
+}
+
+func TestDescribeNotRegistered(t *testing.T) {
+	r := router.New()
+	r.GET("/users", Index)
+	for _, route := range []struct{ Method, Path string }{
+		{"POST", "/users"},
+		{"GET", "/users/:id"},
+	} {
+		func() {
+			defer func() {
+				assert.Equal(t, true, recover() != nil)
+			}()
+			r.Describe(route.Method, route.Path, router.Metadata{})
+			t.Errorf("route %s %s should not be registered", route.Method, route.Path)
+		}()
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	context "golang.org/x/net/context"
 
@@ -61,19 +62,28 @@ func (fn WrapErr) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // Router ..
 type Router struct {
-	trie              *trie
-	notFoundHandlerFn HandlerFunc
+	tree                      *tree
+	routes                    []*data // in order of registration
+	notFoundHandlerFn         HandlerFunc
+	methodNotAllowedHandlerFn HandlerFunc
 }
 
 // NewRouter creates a new router instance
 func NewRouter() *Router {
 	return &Router{
-		trie:              newTrie(),
-		notFoundHandlerFn: NotFoundHandler,
+		tree:                      newTree(),
+		notFoundHandlerFn:         NotFoundHandler,
+		methodNotAllowedHandlerFn: MethodNotAllowedHandler,
 	}
 }
 
 // Handle takes a method, pattern, and http handler for a route.
+// Besides named params, e.g. /home/:id, patterns may have params constrained
+// by a regular expression or one of ParamTypes, e.g. /home/:id{[0-9]+} or
+// /home/:id{uuid}, a catch-all param as last segment, e.g. /files/*path, and
+// optional trailing segments, e.g. /archive/:year?. When several routes match
+// a path, static segments take precedence over constrained params, then
+// params and then catch-alls, from the first segment on.
 func (r *Router) Handle(method, path string, handler Handler) {
 	if matches, err := regexp.MatchString("^[A-Z]+$", method); !matches || err != nil {
 		panic("Http method " + method + " is not valid")
@@ -81,16 +91,25 @@ func (r *Router) Handle(method, path string, handler Handler) {
 	if path[0] != '/' {
 		panic("Path must start with /")
 	}
-	key, value, prefix, vars := transformPath(path)
-	data := r.trie.Get(key)
-	if data == nil {
-		data = newData()
+	for _, p := range expandOptional(path) {
+		r.handle(method, p, path, handler)
+	}
+}
+
+// handle registers the route of path, from the pattern given to Handle
+func (r *Router) handle(method, path, pattern string, handler Handler) {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
 	}
-	data.value = value
-	data.prefix = prefix
-	data.vars = vars
-	data.methods[method] = handler.ServeHTTP
-	r.trie.Put(key, data)
+	n := r.tree.insert(path)
+	if n.route == nil {
+		n.route = newData()
+		r.routes = append(r.routes, n.route)
+	}
+	n.route.pattern = pattern
+	n.route.path = path
+	n.route.vars = params(path)
+	n.route.methods[method] = handler.ServeHTTP
 }
 
 // HandleFunc registers the handler function for the given pattern.
@@ -118,23 +137,51 @@ func (r *Router) DELETE(path string, handlerFn HandlerFunc) {
 	r.HandleFunc("DELETE", path, handlerFn)
 }
 
-// ServeHTTP
+// PATCH is a shortcut for Handle with method "PATCH"
+func (r *Router) PATCH(path string, handlerFn HandlerFunc) {
+	r.HandleFunc("PATCH", path, handlerFn)
+}
+
+// HEAD is a shortcut for Handle with method "HEAD"
+// Note: GET routes already answer HEAD requests
+func (r *Router) HEAD(path string, handlerFn HandlerFunc) {
+	r.HandleFunc("HEAD", path, handlerFn)
+}
+
+// OPTIONS is a shortcut for Handle with method "OPTIONS"
+// Note: routes without OPTIONS handler answer with the methods allowed
+func (r *Router) OPTIONS(path string, handlerFn HandlerFunc) {
+	r.HandleFunc("OPTIONS", path, handlerFn)
+}
+
+// ServeHTTP dispatches the request to the handler of the route matched.
+// Requests to a route with a method not registered are answered with
+// 405 Method Not Allowed, or 204 No Content if OPTIONS, and the methods
+// allowed in the Allow header.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	m := r.findMatch(req)
-	if m == nil {
+	data, ctx := r.lookup(req)
+	if data == nil {
 		r.notFoundHandlerFn(w, req)
 		return
 	}
-	m.ServeHTTP(w, req)
+	req = req.WithContext(ctx)
+	if h, ok := data.handler(req.Method); ok {
+		h.ServeHTTP(w, req)
+		return
+	}
+	w.Header().Set("Allow", data.allow())
+	if req.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	r.methodNotAllowedHandlerFn(w, req)
 }
 
 // WrapperMiddleware ..
 func (r *Router) WrapperMiddleware(mids ...Middleware) {
-	for _, k := range r.trie.Keys() {
-		data := r.trie.Get(k)
+	for _, data := range r.routes {
 		for m, h := range data.methods {
 			data.methods[m] = Wrap(h, mids...)
-			r.trie.Put(k, data)
 		}
 	}
 }
@@ -144,6 +191,13 @@ func (r *Router) NotFoundHandler(handlerFn HandlerFunc) {
 	r.notFoundHandlerFn = handlerFn
 }
 
+// MethodNotAllowedHandler is configuraton method to alow clients to customize
+// the handler of requests with a method not allowed, the Allow header is
+// already set
+func (r *Router) MethodNotAllowedHandler(handlerFn HandlerFunc) {
+	r.methodNotAllowedHandlerFn = handlerFn
+}
+
 // FileServer returns a handler that serves HTTP requests
 // with the contents of the file system rooted at root.
 func (r *Router) FileServer(path string, root http.Dir) {
@@ -168,173 +222,39 @@ func (r *Router) FileServer(path string, root http.Dir) {
 	})
 }
 
-// func (r *Router) findMatch(req *http.Request) *Match {
-// 	path := req.URL.Path
-// 	method := req.Method
-// 	data, values := findData(r, method, path, "", "", "", []string{})
-// 	if data != nil {
-// 		ctx := setContext(req.Context(), data.vars, values)
-// 		handler := data.methods[req.Method]
-// 		return &Match{handler: handler, ctx: ctx}
-// 	}
-// 	return nil
-// }
-
 func (r *Router) findMatch(req *http.Request) *Match {
-	path := req.URL.Path
-	paths := validPaths(path, "", "", "", nil, nil)
-	for key, values := range paths {
-		data := r.trie.Get(key)
-		if data != nil {
-			ctx := setContext(req.Context(), data.vars, values)
-			handler, ok := data.methods[req.Method]
-			if !ok {
-				return nil
-			}
-			return &Match{handler: handler, ctx: ctx}
-		}
-	}
-	return nil
-}
-
-// transformPath returns a tuple with key, value, prefix and params for the
-// for the presented path
-// e.g. /home/:id/room -> /home/:/room, /room, /home/:, [id]
-func transformPath(path string) (key, value, prefix string, params []string) {
-	params = []string{}
-	if path == "/" {
-		return "/", "/", "", params
-	}
-	if path[0] != '/' {
-		panic("Path must start with '/'")
-	}
-	segments := strings.Split(path, "/")[1:]
-	for _, s := range segments {
-		if s[0] == ':' {
-			params = append(params, s[1:])
-			path = strings.Replace(path, s, ":", 1)
-		}
+	data, ctx := r.lookup(req)
+	if data == nil {
+		return nil
 	}
-	key = path
-	value = path[strings.LastIndex(key, "/"):]
-	if i := strings.LastIndex(key, "/"); i != 0 {
-		prefix = path[:i]
+	handler, ok := data.handler(req.Method)
+	if !ok {
+		return nil
 	}
-	return key, value, prefix, params
+	return &Match{handler: handler, ctx: ctx}
 }
 
-// validPaths returns candidate paths to be searched on trie
-// eg. /home/123/room -> {"/home/123/room":[], "/home/123/:":["room"],
-// "/home/:/room":["123"], "/:/123/room":["home"], "/home/:/:":["123","room"],
-// "/:/123/:":["home","room"], "/:/:/room":["home","123"], "/:/:/:":["home","123","room"]}
-func validPaths(path, key, segment, cutset string, track []string, out map[string][]string) map[string][]string {
-	// fmt.Printf("validPaths path %v, key %v, segment %v, cutset %v, track %v, out %v\n", path, key, segment, cutset, track, out)
-	if out == nil {
-		out = make(map[string][]string)
-	}
-	if path == "/" {
-		out[path] = []string{}
-		return out
-	}
-	// first iteration
-	if path != "" && len(key) == 0 {
-		// remove trailing slash `/`
-		if len(path) > 1 && path[len(path)-1:] == `/` {
-			path = path[:len(path)-1]
-		}
-		key = path
-		cutset = path
-		out[key] = []string{}
-	}
-	// all done end iteration
-	if strings.Count(path, "/") == strings.Count(key, ":") {
-		return out
-	}
-
-	// TODO maybe try to use Regex
-	i := strings.Index(cutset[1:], "/")
-	if i == -1 {
-		segment = cutset[1:]
-		key = path[:strings.LastIndex(path, segment)] + strings.Replace(path[strings.LastIndex(path, segment):], segment, ":", 1)
-		tmp := []string{segment}
-		track = append(tmp, track...)
-		out[key] = append(out[key], track...)
-		// prepare inputs
-		cutset = key[:strings.Index(key, "/:")]
-		return validPaths(key, key, segment, cutset, track, out)
+// lookup returns the data of the route matching the request path, whatever
+// the method, and the request context with the route params and pattern
+func (r *Router) lookup(req *http.Request) (*data, context.Context) {
+	vp := valuesPool.Get().(*[]string)
+	defer valuesPool.Put(vp)
+	data, values := r.tree.lookup(req.URL.Path, (*vp)[:0])
+	*vp = values[:0]
+	if data == nil {
+		return nil, nil
 	}
-	segment = cutset[1 : i+1]
-	key = strings.Replace(path, segment, ":", 1)
-	out[key] = append(out[key], segment)
-	out[key] = append(out[key], track...)
-	cutset = cutset[i+1:]
-	return validPaths(path, key, segment, cutset, track, out)
-
+	ctx := setContext(req.Context(), data.vars, values)
+	ctx = context.WithValue(ctx, patternKey{}, data.pattern)
+	return data, ctx
 }
 
-// findData.. deprecated
-func findData(r *Router, method, path, suffix, key, segment string, values []string) (*data, []string) {
-	// log.Printf("findData method:%v path:%v suffix:%v key:%v segment:%v values:%v\n", method, path, suffix, key, segment, values)
-	// initialize
-	if path != "" && suffix == "" && key == "" {
-		// remove trailing slash `/`
-		if len(path) > 1 && strings.LastIndex(path, "/") == len(path)-1 {
-			path = path[:len(path)-1]
-		}
-		suffix = path
-		key = path
-	}
-
-	// test key
-	if key != "" {
-		// if key is valid returns
-
-		if d := r.trie.Get(key); d != nil {
-			return d, values
-		}
-		// if d := r.trie.Get(key); d.methods[method] != nil {
-		// 	return d, values
-		// }
-
-		// Nothing found, returns nil
-		if c := strings.Count(key, ":"); c != 0 && c == strings.Count(key, "/") {
-			return nil, []string{}
-		}
-
-		// restore values
-		if len(values) > 1 {
-			// remove last segment in the slice
-			values = append(values[:len(values)-1], values[:len(values)-2]...)
-		} else {
-			values = []string{}
-		}
-	}
-
-	// initialize for the inner loop
-	// : stays fixed in the last segment
-	if suffix == "" {
-		x := strings.Index(key, "/:")
-		suffix = key[:x]
-		values = append(values, segment)
-		path = key
-	}
-
-	// TODO maybe try to use Regex
-	i := strings.Index(suffix[1:], "/")
-	if i == -1 {
-		segment = suffix[1:]
-		key = path[:strings.LastIndex(path, segment)] + strings.Replace(path[strings.LastIndex(path, segment):], segment, ":", 1)
-		// key = strings.Replace(path, segment, ":", 1)
-		suffix = ""
-	} else {
-		segment = suffix[1 : i+1]
-		key = strings.Replace(path, segment, ":", 1)
-		suffix = suffix[i+1:]
-	}
-	values = append(values, segment)
-
-	d, v := findData(r, method, path, suffix, key, segment, values)
-	return d, v
+// valuesPool holds the buffers of param values used by lookups
+var valuesPool = sync.Pool{
+	New: func() interface{} {
+		v := make([]string, 0, 8)
+		return &v
+	},
 }
 
 // func setContext(ctx context.Context, vars, values []string) context.Context {
@@ -385,6 +305,15 @@ func DefaultRootHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, "Hello World!\n")
 }
 
+// MethodNotAllowedHandler default method not allowed json handler
+func MethodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	reply.Json(w, r, http.StatusMethodNotAllowed, &Err{
+		Type:    "invalid_request_error",
+		Message: fmt.Sprintf("Method %v not allowed. path: %v allowed: %v", r.Method, r.URL.EscapedPath(), w.Header().Get("Allow")),
+	})
+}
+
 // NotFoundHandler default not found resource json handler
 func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("X-Content-Type-Options", "nosniff")
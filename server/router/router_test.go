@@ -189,9 +189,9 @@ func TestDynamicRouter(t *testing.T) {
 		{
 			Method: "GET",
 			Path:   "/home/",
-			// Body:         strings.NewReader(`{"type":"invalid_request_error", "message": "Invalid request errors arise when your request has invalid parameters."}`),
-			BodyContains: map[string]string{"type": "invalid_request_error", "message": "Invalid request errors arise when your request has invalid parameters. path: /home/ query: "},
-			Status:       http.StatusNotFound,
+			// the route exists for POST only
+			BodyContains: map[string]string{"type": "invalid_request_error", "message": "Method GET not allowed. path: /home/ allowed: OPTIONS, POST"},
+			Status:       http.StatusMethodNotAllowed,
 		},
 		{
 			Method: "GET",
@@ -268,3 +268,91 @@ func BenchmarkRouter(b *testing.B) {
 		response.Body.Close()
 	}
 }
+
+// go test ./server/router -run=^$ -bench=BenchmarkServeHTTP -benchmem
+func BenchmarkServeHTTP(b *testing.B) {
+	r := router.New()
+	r.GET("/home/:id/room/:category", func(w http.ResponseWriter, r *http.Request) {})
+	req := httptest.NewRequest("GET", "/home/456/room/999", nil)
+	w := httptest.NewRecorder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		r.ServeHTTP(w, req)
+	}
+}
+
+func TestPatternFromContext(t *testing.T) {
+	var pattern string
+	r := router.New()
+	r.GET("/home/:id/room", func(w http.ResponseWriter, r *http.Request) {
+		pattern = router.PatternFromContext(r.Context())
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/home/123/room", nil))
+	assert.Equal(t, "/home/:id/room", pattern)
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	r := router.New()
+	r.GET("/rooms/:id", Index)
+	r.PUT("/rooms/:id", Index)
+	r.PATCH("/rooms/:id", Index)
+	r.POST("/rooms", Index)
+	r.OPTIONS("/rooms", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", "POST")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var tests = []struct {
+		Method string
+		Path   string
+		Status int
+		Allow  string
+		Body   string
+	}{
+		{Method: "PATCH", Path: "/rooms/1", Status: http.StatusOK, Body: "Hello World"},
+		// HEAD is answered by GET handlers, without body
+		{Method: "HEAD", Path: "/rooms/1", Status: http.StatusOK},
+		{Method: "DELETE", Path: "/rooms/1", Status: http.StatusMethodNotAllowed, Allow: "GET, HEAD, OPTIONS, PATCH, PUT"},
+		{Method: "OPTIONS", Path: "/rooms/1", Status: http.StatusNoContent, Allow: "GET, HEAD, OPTIONS, PATCH, PUT"},
+		{Method: "GET", Path: "/rooms", Status: http.StatusMethodNotAllowed, Allow: "OPTIONS, POST"},
+		// OPTIONS handlers registered are kept
+		{Method: "OPTIONS", Path: "/rooms", Status: http.StatusOK, Allow: "POST"},
+		{Method: "DELETE", Path: "/halls/1", Status: http.StatusNotFound},
+	}
+	server := httptest.NewServer(r)
+	defer server.Close()
+	for _, test := range tests {
+		req, err := http.NewRequest(test.Method, server.URL+test.Path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, test.Status, resp.StatusCode)
+		assert.Equal(t, test.Allow, resp.Header.Get("Allow"))
+		if test.Body != "" {
+			assert.Equal(t, test.Body, string(body))
+		}
+		if test.Method == "HEAD" {
+			assert.Equal(t, 0, len(body))
+		}
+	}
+}
+
+func TestMethodNotAllowedHandler(t *testing.T) {
+	r := router.New()
+	r.POST("/rooms", Index)
+	r.MethodNotAllowedHandler(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "use "+w.Header().Get("Allow"), http.StatusMethodNotAllowed)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/rooms", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "use OPTIONS, POST\n", w.Body.String())
+}
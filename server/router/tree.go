@@ -0,0 +1,225 @@
+// Package router matches http requests to handlers by method and path, with
+// routes kept in a compressed radix tree. Static text shared by routes is
+// stored once, and params are matched segment by segment, so the cost of
+// a lookup depends on the length of the path rather than on the number of
+// routes.
+package router
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//
+// TREE
+//
+
+// tree of routes
+type tree struct {
+	root *node
+}
+
+// newTree creates new instance tree
+func newTree() *tree {
+	return &tree{root: &node{}}
+}
+
+// node of the tree. Static children are matched by the text of their path,
+// dynamic children match a whole path segment, preceded by /.
+type node struct {
+	path        string  // static text matched by the node, empty for dynamic nodes
+	indices     string  // first byte of the path of each static child
+	children    []*node // static children
+	constrained []*node // constrained param children, in order of registration
+	param       *node   // param child
+	catchAll    *node   // catch-all child
+	constraint  string  // regular expression of a constrained param node
+	re          *regexp.Regexp
+	route       *data // route ending at the node
+}
+
+// insert adds the nodes of the route pattern path, and returns the node it
+// ends at
+func (t *tree) insert(path string) *node {
+	n := t.root
+	static := ""
+	for _, s := range parseSegments(path) {
+		if s.kind == staticSegment {
+			static += "/" + s.value
+			continue
+		}
+		n = n.addStatic(static)
+		static = ""
+		switch s.kind {
+		case constrainedSegment:
+			n = n.addConstrained(s.re)
+		case paramSegment:
+			if n.param == nil {
+				n.param = &node{}
+			}
+			n = n.param
+		case catchAllSegment:
+			if n.catchAll == nil {
+				n.catchAll = &node{}
+			}
+			n = n.catchAll
+		}
+	}
+	if path == "/" {
+		static = "/"
+	}
+	return n.addStatic(static)
+}
+
+// addStatic adds the static text s below n, splitting nodes sharing a
+// prefix with s, and returns the node it ends at
+func (n *node) addStatic(s string) *node {
+	if s == "" {
+		return n
+	}
+	i := strings.IndexByte(n.indices, s[0])
+	if i == -1 {
+		c := &node{path: s}
+		n.indices += s[:1]
+		n.children = append(n.children, c)
+		return c
+	}
+	c := n.children[i]
+	l := commonPrefix(c.path, s)
+	if l < len(c.path) {
+		// c keeps the common prefix, the rest moves to a new child
+		tail := *c
+		tail.path = c.path[l:]
+		*c = node{path: c.path[:l], indices: tail.path[:1], children: []*node{&tail}}
+	}
+	return c.addStatic(s[l:])
+}
+
+// addConstrained returns the constrained param child of n with the
+// regular expression re, added if not yet
+func (n *node) addConstrained(re *regexp.Regexp) *node {
+	for _, c := range n.constrained {
+		if c.constraint == re.String() {
+			return c
+		}
+	}
+	c := &node{constraint: re.String(), re: re}
+	n.constrained = append(n.constrained, c)
+	return c
+}
+
+// lookup returns the route matching path, and the values of its params
+// appended to values. Static text precedes constrained params, which precede
+// params, which precede catch-alls, from the start of the path on.
+// Note: no allocations are made if values has enough capacity.
+func (t *tree) lookup(path string, values []string) (*data, []string) {
+	if len(path) > 1 && path[len(path)-1] == '/' {
+		path = path[:len(path)-1]
+	}
+	return t.root.lookup(path, values)
+}
+
+func (n *node) lookup(path string, values []string) (*data, []string) {
+	if !strings.HasPrefix(path, n.path) {
+		return nil, values
+	}
+	path = path[len(n.path):]
+	if path == "" {
+		if n.route != nil {
+			return n.route, values
+		}
+	} else if i := strings.IndexByte(n.indices, path[0]); i != -1 {
+		if d, v := n.children[i].lookup(path, values); d != nil {
+			return d, v
+		}
+	}
+	if path != "" && path[0] != '/' {
+		return nil, values
+	}
+	if (n.constrained != nil || n.param != nil) && len(path) > 1 {
+		end := strings.IndexByte(path[1:], '/') + 1
+		if end == 0 {
+			end = len(path)
+		}
+		value, rest := path[1:end], path[end:]
+		for _, c := range n.constrained {
+			if !c.re.MatchString(value) {
+				continue
+			}
+			if d, v := c.lookup(rest, append(values, value)); d != nil {
+				return d, v
+			}
+		}
+		if n.param != nil && value != "" {
+			if d, v := n.param.lookup(rest, append(values, value)); d != nil {
+				return d, v
+			}
+		}
+	}
+	if n.catchAll != nil && n.catchAll.route != nil {
+		if path != "" {
+			path = path[1:]
+		}
+		return n.catchAll.route, append(values, path)
+	}
+	return nil, values
+}
+
+// commonPrefix returns the length of the prefix shared by a and b
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+//
+// DATA
+//
+
+// Data a data struct that each node can handle
+type data struct {
+	pattern string   // route as registered, e.g. /home/:id
+	path    string   // path of the node, optional segments of pattern resolved
+	vars    []string // names of the params, in order
+	methods map[string]HandlerFunc
+	meta    map[string]Metadata // metadata by method, as described
+}
+
+// newData returns a new data instance
+func newData() *data {
+	return &data{
+		vars:    []string{},
+		methods: make(map[string]HandlerFunc),
+		meta:    make(map[string]Metadata),
+	}
+}
+
+// handler returns the handler of method, GET handlers answer HEAD requests
+func (d *data) handler(method string) (HandlerFunc, bool) {
+	h, ok := d.methods[method]
+	if !ok && method == "HEAD" {
+		h, ok = d.methods["GET"]
+	}
+	return h, ok
+}
+
+// allow returns the methods allowed, as in the Allow header
+func (d *data) allow() string {
+	methods := make([]string, 0, len(d.methods)+2)
+	for m := range d.methods {
+		methods = append(methods, m)
+	}
+	if _, ok := d.methods["GET"]; ok {
+		if _, ok := d.methods["HEAD"]; !ok {
+			methods = append(methods, "HEAD")
+		}
+	}
+	if _, ok := d.methods["OPTIONS"]; !ok {
+		methods = append(methods, "OPTIONS")
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
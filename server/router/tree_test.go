@@ -0,0 +1,153 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/paulormart/assert"
+)
+
+func newTestTree(patterns ...string) *tree {
+	t := newTree()
+	for _, p := range patterns {
+		d := newData()
+		d.pattern = p
+		d.vars = params(p)
+		t.insert(p).route = d
+	}
+	return t
+}
+
+func TestTreeInsert(t *testing.T) {
+	tr := newTestTree("/", "/home", "/homes", "/house/:id", "/house/:id/room", "/hotel/*path")
+	// static text shared by routes is stored once
+	assert.Equal(t, "/", tr.root.children[0].path)
+	assert.Equal(t, 1, len(tr.root.children))
+	ho := tr.root.children[0].children[0]
+	assert.Equal(t, "ho", ho.path)
+	assert.Equal(t, "mut", ho.indices)
+	assert.Equal(t, "s", ho.children[0].children[0].path)
+	assert.Equal(t, true, ho.children[1].param != nil)
+	assert.Equal(t, true, ho.children[2].catchAll != nil)
+	// inserting a route again ends at the same node
+	assert.Equal(t, tr.insert("/house/:id"), tr.insert("/house/:name"))
+}
+
+func TestTreeLookup(t *testing.T) {
+	tr := newTestTree(
+		"/",
+		"/home",
+		"/homes",
+		"/home/:id",
+		"/home/:id/room/:category",
+		"/home/new",
+		"/users/:id{[0-9]+}",
+		"/users/:name",
+		"/files/*path",
+		"/files/readme",
+	)
+	var tests = []struct {
+		Path    string
+		Pattern string
+		Values  []string
+	}{
+		{Path: "/", Pattern: "/", Values: []string{}},
+		{Path: "/home", Pattern: "/home", Values: []string{}},
+		{Path: "/home/", Pattern: "/home", Values: []string{}},
+		{Path: "/homes", Pattern: "/homes", Values: []string{}},
+		{Path: "/homey"},
+		{Path: "/home/new", Pattern: "/home/new", Values: []string{}},
+		{Path: "/home/newer", Pattern: "/home/:id", Values: []string{"newer"}},
+		{Path: "/home/123/room/kitchen", Pattern: "/home/:id/room/:category", Values: []string{"123", "kitchen"}},
+		{Path: "/home/123/room"},
+		{Path: "/users/123", Pattern: "/users/:id{[0-9]+}", Values: []string{"123"}},
+		{Path: "/users/gopher", Pattern: "/users/:name", Values: []string{"gopher"}},
+		{Path: "/users"},
+		{Path: "/files", Pattern: "/files/*path", Values: []string{""}},
+		{Path: "/files/readme", Pattern: "/files/readme", Values: []string{}},
+		{Path: "/files/docs/guide.md", Pattern: "/files/*path", Values: []string{"docs/guide.md"}},
+		{Path: "/filesystem"},
+	}
+	for _, test := range tests {
+		d, values := tr.lookup(test.Path, []string{})
+		if test.Pattern == "" {
+			assert.Equal(t, (*data)(nil), d)
+			continue
+		}
+		if d == nil {
+			t.Fatalf("%s not found", test.Path)
+		}
+		assert.Equal(t, test.Pattern, d.pattern)
+		assert.Equal(t, test.Values, values)
+	}
+}
+
+func TestTreeLookupAllocs(t *testing.T) {
+	tr := newTestTree("/home/:id/room/:category", "/files/*path", "/users/:id{[0-9]+}")
+	values := make([]string, 0, 8)
+	for _, path := range []string{"/home/123/room/kitchen", "/files/docs/guide.md", "/users/123", "/none"} {
+		allocs := testing.AllocsPerRun(100, func() {
+			tr.lookup(path, values[:0])
+		})
+		assert.Equal(t, float64(0), allocs)
+	}
+}
+
+// deepPattern returns a pattern of depth segments, every other a param
+// e.g. /s0/:p1/s2/:p3
+func deepPattern(depth int) (pattern, path string) {
+	var p, q strings.Builder
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			fmt.Fprintf(&p, "/s%d", i)
+			fmt.Fprintf(&q, "/s%d", i)
+			continue
+		}
+		fmt.Fprintf(&p, "/:p%d", i)
+		fmt.Fprintf(&q, "/v%d", i)
+	}
+	return p.String(), q.String()
+}
+
+func benchmarkLookup(b *testing.B, tr *tree, path string) {
+	values := make([]string, 0, 32)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if d, _ := tr.lookup(path, values[:0]); d == nil {
+			b.Fatalf("%s not found", path)
+		}
+	}
+}
+
+// go test ./server/router -run=^$ -bench=BenchmarkLookup -benchmem
+func BenchmarkLookupStatic(b *testing.B) {
+	benchmarkLookup(b, newTestTree("/", "/home", "/home/room", "/users"), "/home/room")
+}
+
+func BenchmarkLookupParams(b *testing.B) {
+	benchmarkLookup(b, newTestTree("/home/:id/room/:category"), "/home/456/room/999")
+}
+
+func BenchmarkLookupDeep(b *testing.B) {
+	for _, depth := range []int{4, 16, 32} {
+		pattern, path := deepPattern(depth)
+		b.Run(fmt.Sprintf("depth-%d", depth), func(b *testing.B) {
+			benchmarkLookup(b, newTestTree(pattern), path)
+		})
+	}
+}
+
+func BenchmarkLookupLarge(b *testing.B) {
+	for _, size := range []int{10, 100, 1000, 10000} {
+		patterns := make([]string, 0, size)
+		for i := 0; i < size; i++ {
+			patterns = append(patterns, fmt.Sprintf("/api/v1/resource%d/:id/items/:item", i))
+		}
+		path := fmt.Sprintf("/api/v1/resource%d/123/items/456", size-1)
+		b.Run(fmt.Sprintf("routes-%d", size), func(b *testing.B) {
+			benchmarkLookup(b, newTestTree(patterns...), path)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/aukbit/pluto/v6/reply"
+)
+
+// routesHandler replies with the http routes served, see RoutesEndpoint
+func routesHandler(w http.ResponseWriter, r *http.Request) {
+	s := FromContext(r.Context())
+	reply.Json(w, r, http.StatusOK, s.cfg.Mux.Routes())
+}
@@ -3,20 +3,26 @@ package server
 import (
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/aukbit/pluto/v6/common"
 	"github.com/aukbit/pluto/v6/discovery"
 	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/aukbit/pluto/v6/server/router/openapi"
 	"github.com/rs/zerolog"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
 
 	"google.golang.org/grpc"
 )
@@ -29,7 +35,10 @@ const (
 // A Server defines parameters for running an HTTP server.
 // The zero value for Server is a valid configuration.
 type Server struct {
-	close chan bool
+	close     chan struct{} // closed to ask the server to stop
+	closeOnce sync.Once
+	done      chan struct{} // closed once the server has stopped
+	started   int32         // set once the listener is being served
 
 	cfg        Config
 	wg         *sync.WaitGroup
@@ -37,6 +46,10 @@ type Server struct {
 	grpcServer *grpc.Server
 	health     *health.Server
 	logger     zerolog.Logger
+	inflight   int64             // number of http requests being served
+	inproc     *bufconn.Listener // in-process listener of the grpc server used by transcoding
+	transcoder *grpc.ClientConn  // in-process connection used by transcoding
+	errs       common.Errors     // errors recorded while applying options
 }
 
 // New returns a new http server with cfg passed in
@@ -48,7 +61,8 @@ func New(opts ...Option) *Server {
 func newServer(opts ...Option) *Server {
 	s := &Server{
 		cfg:    newConfig(),
-		close:  make(chan bool),
+		close:  make(chan struct{}),
+		done:   make(chan struct{}),
 		wg:     &sync.WaitGroup{},
 		health: health.NewServer(),
 	}
@@ -74,6 +88,10 @@ func (s *Server) Run(opts ...Option) error {
 	for _, opt := range opts {
 		opt.apply(s)
 	}
+	// do not start a half configured server
+	if err := s.Validate(); err != nil {
+		return err
+	}
 	s.logger = s.logger.With().Dict("server", zerolog.Dict().
 		Str("id", s.cfg.ID).
 		Str("name", s.cfg.Name).
@@ -96,12 +114,17 @@ func (s *Server) Run(opts ...Option) error {
 	return nil
 }
 
-// Stop stops server by sending a message to close the listener via channel
+// Stop stops server by closing the close channel and, if the server was
+// started, waits until in-flight requests are drained. It is safe to call
+// more than once and on a server that never started.
 func (s *Server) Stop() {
 	// set health as not serving
 	s.health.SetServingStatus(s.cfg.ID, 2)
 	// close listener
-	s.close <- true
+	s.closeOnce.Do(func() { close(s.close) })
+	if atomic.LoadInt32(&s.started) == 1 {
+		<-s.done
+	}
 }
 
 func (s *Server) Health() *healthpb.HealthCheckResponse {
@@ -117,14 +140,65 @@ func (s *Server) Health() *healthpb.HealthCheckResponse {
 		s.logger.Error().Msg(err.Error())
 		return &healthpb.HealthCheckResponse{Status: 2}
 	}
+	// clients can not connect once the certificate has expired
+	if s.cfg.Certificates != nil && s.cfg.Certificates.CheckExpiry().Expired {
+		return &healthpb.HealthCheckResponse{Status: 2}
+	}
 	return hcr
 }
 
+// Validate returns the errors recorded by options together with any
+// configuration that would prevent the server from running
+func (s *Server) Validate() error {
+	errs := append(common.Errors{}, s.errs...)
+	if _, err := common.Port(s.cfg.addr()); err != nil {
+		errs = append(errs, err)
+	}
+	switch s.cfg.Format {
+	case "grpc":
+		if s.cfg.GRPCRegister == nil {
+			errs = append(errs, errors.New("grpc server requires a GRPCRegister function"))
+		}
+	case "https":
+		if s.cfg.TLSConfig == nil {
+			errs = append(errs, errors.New("https server requires a TLSConfig"))
+		}
+	case "combined":
+		if s.cfg.GRPCRegister == nil {
+			errs = append(errs, errors.New("combined server requires a GRPCRegister function"))
+		}
+	}
+	if s.cfg.Transcoding && s.cfg.Format != "combined" {
+		errs = append(errs, errors.New("transcoding requires the combined format"))
+	}
+	if s.cfg.ClientCAs != nil && s.cfg.TLSConfig == nil {
+		errs = append(errs, errors.New("client certificate verification requires a TLSConfig"))
+	}
+	if s.cfg.GRPCWeb && s.cfg.Format != "grpc" && s.cfg.Format != "combined" {
+		errs = append(errs, errors.New("grpc-web requires the grpc or combined format"))
+	}
+	if s.cfg.AccessLogFormat != AccessLogJSON && s.cfg.AccessLogFormat != AccessLogCombined {
+		errs = append(errs, fmt.Errorf("unknown access log format %q", s.cfg.AccessLogFormat))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	for i, err := range errs {
+		errs[i] = fmt.Errorf("%s: %v", s.Name(), err)
+	}
+	return errs
+}
+
 // Name returns server name
 func (s *Server) Name() string {
 	return s.cfg.Name
 }
 
+// Addr returns server address
+func (s *Server) Addr() string {
+	return s.cfg.addr()
+}
+
 // Logger returns server logger
 func (s *Server) Logger() zerolog.Logger {
 	return s.logger
@@ -136,11 +210,18 @@ func (s *Server) setHTTPServer() {
 	}
 	// set health check handler
 	s.cfg.Mux.GET("/_health", router.Wrap(healthHandler))
+	if s.cfg.RoutesEndpoint {
+		s.cfg.Mux.GET("/_routes", routesHandler)
+		s.cfg.Mux.GET("/_routes/openapi.json", openapi.Handler(s.cfg.Mux, openapi.Info{
+			Title:       s.Name(),
+			Description: s.cfg.Description,
+		}))
+	}
 
 	s.cfg.mu.Lock()
 	// append logger
 	s.cfg.Middlewares = append(s.cfg.Middlewares,
-		loggerMiddleware(s), eidMiddleware(s), serverMiddleware(s),
+		recoveryMiddleware(s), metricsMiddleware(s), loggerMiddleware(s), tracingMiddleware(s), eidMiddleware(s), serverMiddleware(s),
 	)
 	// wrap Middlewares
 	s.cfg.Mux.WrapperMiddleware(s.cfg.Middlewares...)
@@ -148,7 +229,7 @@ func (s *Server) setHTTPServer() {
 	// initialize http server
 	s.httpServer = &http.Server{
 		// handler to invoke, http.DefaultServeMux if nil
-		Handler: s.cfg.Mux,
+		Handler: s.countInflight(s.cfg.Mux),
 
 		// ReadTimeout is used by the http server to set a maximum duration before
 		// timing out read of the request. The default timeout is 10 seconds.
@@ -166,6 +247,12 @@ func (s *Server) start() (err error) {
 	s.logger.Info().Msg(fmt.Sprintf("starting %s %s, listening on %s", s.cfg.Format, s.Name(), s.cfg.Addr))
 	var ln net.Listener
 
+	if s.cfg.TLSConfig != nil && s.cfg.ClientCAs != nil {
+		// mutual TLS
+		s.cfg.TLSConfig.ClientCAs = s.cfg.ClientCAs
+		s.cfg.TLSConfig.ClientAuth = s.cfg.ClientAuth
+	}
+
 	switch s.cfg.Format {
 	case "https":
 		// append strict security header
@@ -176,6 +263,17 @@ func (s *Server) start() (err error) {
 		if err != nil {
 			return err
 		}
+	case "combined":
+		// TLS, if any, is negotiated by the http server to offer h2 over ALPN
+		if s.cfg.TLSConfig != nil {
+			s.cfg.mu.Lock()
+			s.cfg.Middlewares = append(s.cfg.Middlewares, strictSecurityHeaderMiddleware())
+			s.cfg.mu.Unlock()
+		}
+		ln, err = s.listen()
+		if err != nil {
+			return err
+		}
 	default:
 		ln, err = s.listen()
 		if err != nil {
@@ -186,9 +284,29 @@ func (s *Server) start() (err error) {
 	switch s.cfg.Format {
 	case "grpc":
 		s.setGRPCServer()
+		if s.cfg.GRPCWeb {
+			// grpc and grpc-web calls are both served as http handlers
+			s.httpServer = &http.Server{TLSConfig: s.cfg.TLSConfig}
+			if err := s.serveCombined(ln); err != nil {
+				return err
+			}
+			break
+		}
 		if err := s.serveGRPC(ln); err != nil {
 			return err
 		}
+	case "combined":
+		s.setGRPCServer()
+		if s.cfg.Transcoding {
+			s.setTranscoding()
+			if err := s.serveGRPC(s.inproc); err != nil {
+				return err
+			}
+		}
+		s.setHTTPServer()
+		if err := s.serveCombined(ln); err != nil {
+			return err
+		}
 	default:
 		s.setHTTPServer()
 		if err := s.serve(ln); err != nil {
@@ -196,12 +314,28 @@ func (s *Server) start() (err error) {
 		}
 	}
 
+	if s.cfg.Certificates != nil {
+		s.wg.Add(1)
+		go s.watchCertificates()
+	}
 	// add go routine to WaitGroup
 	s.wg.Add(1)
+	atomic.StoreInt32(&s.started, 1)
 	go s.waitUntilStop(ln)
 	return nil
 }
 
+// watchCertificates reloads the certificates on change until the server stops
+func (s *Server) watchCertificates() {
+	defer s.wg.Done()
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-s.close
+		cancel()
+	}()
+	s.cfg.Certificates.Watch(ctx)
+}
+
 // listen based on http.ListenAndServe
 // listens on the TCP network address srv.Addr
 // If srv.Addr is blank, ":http" is used.
@@ -250,7 +384,7 @@ func (s *Server) serve(ln net.Listener) error {
 	go func(s *Server, ln net.Listener) {
 		defer s.wg.Done()
 		if err := s.httpServer.Serve(ln); err != nil {
-			if err.Error() == errClosing(ln).Error() {
+			if err == http.ErrServerClosed || err.Error() == errClosing(ln).Error() {
 				return
 			}
 			s.logger.Error().Msg(err.Error())
@@ -269,24 +403,67 @@ func errClosing(ln net.Listener) error {
 // waitUntilStop waits for close channel
 func (s *Server) waitUntilStop(ln net.Listener) {
 	defer s.wg.Done()
+	defer close(s.done)
 	// Waits for call to stop
 	<-s.close
-	s.unregister()
+	if err := s.unregister(); err != nil {
+		s.logger.Error().Msg(err.Error())
+	}
 	switch s.cfg.Format {
 	case "grpc":
+		if s.cfg.GRPCWeb {
+			s.shutdownHTTP()
+			s.grpcServer.Stop()
+			break
+		}
 		s.grpcServer.GracefulStop()
+	case "combined":
+		// grpc calls are http handlers, drained by shutdownHTTP
+		s.shutdownHTTP()
+		s.grpcServer.Stop()
+		if s.transcoder != nil {
+			s.transcoder.Close()
+		}
 	default:
-		if err := ln.Close(); err != nil {
+		s.shutdownHTTP()
+	}
+}
+
+// shutdownHTTP waits PreStopDelay for load balancers to notice the server is
+// not serving, then stops accepting connections and waits up to DrainTimeout
+// for in-flight requests to complete before closing the remaining ones
+func (s *Server) shutdownHTTP() {
+	time.Sleep(s.cfg.PreStopDelay)
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.DrainTimeout)
+	defer cancel()
+	err := s.httpServer.Shutdown(ctx)
+	if err == nil {
+		err = s.waitInflight(ctx)
+	}
+	if err != nil {
+		s.logger.Warn().Int64("inflight", atomic.LoadInt64(&s.inflight)).
+			Msg(fmt.Sprintf("%s drain timeout of %v exceeded, %d requests cut off", s.Name(), s.cfg.DrainTimeout, atomic.LoadInt64(&s.inflight)))
+		if err := s.httpServer.Close(); err != nil {
 			s.logger.Error().Msg(err.Error())
 		}
 	}
 }
 
+// countInflight keeps track of the number of http requests being served
+func (s *Server) countInflight(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&s.inflight, 1)
+		defer atomic.AddInt64(&s.inflight, -1)
+		h.ServeHTTP(w, r)
+	})
+}
+
 // register Server within the service discovery system
 func (s *Server) register() error {
 	if _, ok := s.cfg.Discovery.(discovery.Discovery); ok {
 		// define service
 		dse := discovery.Service{
+			ID:      s.cfg.ID,
 			Name:    s.cfg.Name,
 			Address: common.IPaddress(),
 			Port:    s.cfg.Port(),
@@ -294,13 +471,22 @@ func (s *Server) register() error {
 		}
 		// define check
 		dck := discovery.Check{
+			ID:                             "check_" + s.cfg.ID,
 			Name:                           fmt.Sprintf("Service '%s' check", s.cfg.Name),
 			Notes:                          fmt.Sprintf("Ensure the server is listening on port %s", s.cfg.Addr),
 			DeregisterCriticalServiceAfter: "10m",
-			HTTP:                           fmt.Sprintf("http://%s:9090/_health/server/%s", common.IPaddress(), s.cfg.Name),
 			Interval:                       "30s",
 			Timeout:                        "1s",
-			ServiceID:                      s.cfg.Name,
+			ServiceID:                      s.cfg.ID,
+		}
+		switch {
+		case s.cfg.HealthCheckURL != "":
+			dck.HTTP = s.cfg.HealthCheckURL
+		case s.cfg.Format == "grpc":
+			// grpc servers have no http health handler of their own
+			dck.TCP = fmt.Sprintf("%s:%d", common.IPaddress(), s.cfg.Port())
+		default:
+			dck.HTTP = fmt.Sprintf("%s://%s:%d/_health", s.cfg.scheme(), common.IPaddress(), s.cfg.Port())
 		}
 		if err := s.cfg.Discovery.Register(discovery.ServicesCfg(dse), discovery.ChecksCfg(dck)); err != nil {
 			return err
@@ -312,7 +498,7 @@ func (s *Server) register() error {
 // unregister Server from the service discovery system
 func (s *Server) unregister() error {
 	if _, ok := s.cfg.Discovery.(discovery.Discovery); ok {
-		if err := s.cfg.Discovery.Unregister(); err != nil {
+		if err := s.cfg.Discovery.Deregister(s.cfg.ID); err != nil {
 			return err
 		}
 	}
@@ -320,7 +506,14 @@ func (s *Server) unregister() error {
 }
 
 func (s *Server) healthHTTP() {
-	r, err := http.Get(fmt.Sprintf(`http://localhost:%d/_health`, s.cfg.Port()))
+	c := http.DefaultClient
+	if s.cfg.TLSConfig != nil {
+		c = &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   s.selfTLSConfig(),
+			DisableKeepAlives: true,
+		}}
+	}
+	r, err := c.Get(fmt.Sprintf(`%s://localhost:%d/_health`, s.cfg.scheme(), s.cfg.Port()))
 	if err != nil {
 		s.logger.Error().Msg(err.Error())
 		s.health.SetServingStatus(s.cfg.ID, 2)
@@ -342,8 +535,25 @@ func (s *Server) healthHTTP() {
 	s.health.SetServingStatus(s.cfg.ID, hcr.Status)
 }
 
+// selfTLSConfig returns the TLS config of the server checking itself, its own
+// certificate is presented in case clients are required to have one
+func (s *Server) selfTLSConfig() *tls.Config {
+	cfg := &tls.Config{
+		InsecureSkipVerify: true,
+		Certificates:       s.cfg.TLSConfig.Certificates,
+	}
+	if s.cfg.Certificates != nil {
+		cfg.GetClientCertificate = s.cfg.Certificates.GetClientCertificate
+	}
+	return cfg
+}
+
 func (s *Server) healthGRPC() {
-	conn, err := grpc.Dial(fmt.Sprintf("localhost:%d", s.cfg.Port()), grpc.WithInsecure())
+	creds := grpc.WithInsecure()
+	if s.cfg.TLSConfig != nil {
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(s.selfTLSConfig()))
+	}
+	conn, err := grpc.Dial(fmt.Sprintf("localhost:%d", s.cfg.Port()), creds)
 	if err != nil {
 		s.logger.Error().Msg(err.Error())
 		s.health.SetServingStatus(s.cfg.ID, 2)
@@ -365,18 +575,29 @@ func (s *Server) setGRPCServer() {
 	s.cfg.mu.Lock()
 	// add default interceptors
 	s.cfg.UnaryServerInterceptors = append(s.cfg.UnaryServerInterceptors,
+		recoveryUnaryServerInterceptor(s),
+		metricsUnaryServerInterceptor(s),
 		loggerUnaryServerInterceptor(s),
+		tracingUnaryServerInterceptor(s),
 		serverUnaryServerInterceptor(s))
 
 	s.cfg.StreamServerInterceptors = append(s.cfg.StreamServerInterceptors,
+		recoveryStreamServerInterceptor(s),
+		metricsStreamServerInterceptor(s),
 		loggerStreamServerInterceptor(s),
+		tracingStreamServerInterceptor(s),
 		serverStreamServerInterceptor(s))
 
-	// initialize grpc server
-	s.grpcServer = grpc.NewServer(
+	opts := []grpc.ServerOption{
 		grpc.UnaryInterceptor(WrapperUnaryServer(s.cfg.UnaryServerInterceptors...)),
 		grpc.StreamInterceptor(WrapperStreamServer(s.cfg.StreamServerInterceptors...)),
-	)
+	}
+	// TLS of combined and grpc-web servers is terminated by the http server
+	if s.cfg.TLSConfig != nil && s.cfg.Format == "grpc" && !s.cfg.GRPCWeb {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.cfg.TLSConfig)))
+	}
+	// initialize grpc server
+	s.grpcServer = grpc.NewServer(opts...)
 	s.cfg.mu.Unlock()
 
 	// register grpc internal health handlers
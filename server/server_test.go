@@ -2,10 +2,12 @@ package server_test
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"testing"
@@ -19,6 +21,7 @@ import (
 	pb "github.com/aukbit/pluto/v6/test/proto"
 	"github.com/paulormart/assert"
 	"golang.org/x/net/context"
+	"golang.org/x/net/http2"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
@@ -33,7 +36,11 @@ func Detail(w http.ResponseWriter, r *http.Request) {
 	reply.Json(w, r, http.StatusOK, fmt.Sprintf("Hello Room %s", router.FromContext(ctx, "id")))
 }
 
-type greeter struct{}
+// greeter only implements SayHello, the generated GreeterServer also
+// requires SayGoodbye and SayGoodbyeAgain
+type greeter struct {
+	pb.UnimplementedGreeterServer
+}
 
 // SayHello implements helloworld.GreeterServer
 func (s *greeter) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
@@ -41,6 +48,8 @@ func (s *greeter) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloR
 }
 
 func TestMain(m *testing.M) {
+	// testing.Short panics before the test flags are parsed
+	flag.Parse()
 	// Define Router
 	mux := router.New()
 	mux.GET("/home", Home)
@@ -166,3 +175,126 @@ func TestGrpcHealthCheck(t *testing.T) {
 	}
 	assert.Equal(t, "SERVING", h.Status.String())
 }
+
+func TestHttpGracefulStop(t *testing.T) {
+	mux := router.New()
+	mux.GET("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond * 300)
+		reply.Json(w, r, http.StatusOK, "done")
+	})
+	s := server.New(
+		server.Name("drain"),
+		server.Addr(":8086"),
+		server.Mux(mux),
+		server.PreStopDelay(time.Millisecond*100),
+		server.DrainTimeout(time.Second),
+	)
+	go s.Run()
+	time.Sleep(time.Millisecond * 100)
+	res := make(chan *http.Response)
+	go func() {
+		r, err := http.Get("http://localhost:8086/slow")
+		if err != nil {
+			t.Error(err)
+		}
+		res <- r
+	}()
+	time.Sleep(time.Millisecond * 50)
+	// in-flight request completes even though stop was requested
+	s.Stop()
+	r := <-res
+	if r == nil {
+		t.Fatal("in-flight request failed")
+	}
+	defer r.Body.Close()
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	// no new connections are accepted once stopped
+	_, err := http.Get("http://localhost:8086/slow")
+	assert.Equal(t, true, err != nil)
+}
+
+func TestCombined(t *testing.T) {
+	mux := router.New()
+	mux.GET("/home", Home)
+	s := server.New(
+		server.Name("combined"),
+		server.Addr(":8088"),
+		server.Mux(mux),
+		server.GRPCRegister(func(g *grpc.Server) {
+			pb.RegisterGreeterServer(g, &greeter{})
+		}),
+		server.Combined(),
+	)
+	go s.Run()
+	defer s.Stop()
+	time.Sleep(time.Millisecond * 100)
+	// http routes
+	r, err := http.Get("http://localhost:8088/home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var message string
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	assert.Equal(t, "Hello World", message)
+	// grpc services on the same port
+	conn, err := grpc.Dial("localhost:8088", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	res, err := pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "Gopher"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Hello Gopher", res.Message)
+	// one health status for both
+	h, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "SERVING", h.Status.String())
+	assert.Equal(t, "SERVING", s.Health().Status.String())
+}
+
+func TestCombinedStopH2C(t *testing.T) {
+	mux := router.New()
+	mux.GET("/home", Home)
+	s := server.New(
+		server.Name("h2c"),
+		server.Addr(":8105"),
+		server.Mux(mux),
+		server.GRPCRegister(func(g *grpc.Server) {
+			pb.RegisterGreeterServer(g, &greeter{})
+		}),
+		server.Combined(),
+	)
+	go s.Run()
+	time.Sleep(time.Millisecond * 100)
+	// prior knowledge h2c connection
+	c, err := net.Dial("tcp", "localhost:8105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	cc, err := (&http2.Transport{AllowHTTP: true}).NewClientConn(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest("GET", "http://localhost:8105/home", nil)
+	r, err := cc.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	// the connection is closed once stopped
+	s.Stop()
+	time.Sleep(time.Millisecond * 100)
+	req, _ = http.NewRequest("GET", "http://localhost:8105/home", nil)
+	_, err = cc.RoundTrip(req)
+	assert.Equal(t, true, err != nil)
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aukbit/pluto/v6/common"
+)
+
+// ErrUnknownSetting is returned by Settings.Set for keys that do not match
+// any server setting
+var ErrUnknownSetting = errors.New("unknown server setting")
+
+// Settings declarative server configuration, e.g. loaded from a YAML or
+// JSON file. Zero values are left to the server defaults.
+type Settings struct {
+	Description  string          `json:"description" yaml:"description"`
+	Addr         string          `json:"addr" yaml:"addr"`
+	ReadTimeout  common.Duration `json:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout common.Duration `json:"write_timeout" yaml:"write_timeout"`
+	PreStopDelay common.Duration `json:"pre_stop_delay" yaml:"pre_stop_delay"`
+	DrainTimeout common.Duration `json:"drain_timeout" yaml:"drain_timeout"`
+	TLSCertFile  string          `json:"tls_cert_file" yaml:"tls_cert_file"`
+	TLSKeyFile   string          `json:"tls_key_file" yaml:"tls_key_file"`
+	TLSClientCA  string          `json:"tls_client_ca_file" yaml:"tls_client_ca_file"`
+}
+
+// Set assigns the string value to the setting key, where key is the
+// YAML/JSON field name, e.g. "addr" or "read_timeout"
+func (st *Settings) Set(key, value string) error {
+	switch key {
+	case "description":
+		st.Description = value
+	case "addr":
+		st.Addr = value
+	case "read_timeout":
+		return st.ReadTimeout.Set(value)
+	case "write_timeout":
+		return st.WriteTimeout.Set(value)
+	case "pre_stop_delay":
+		return st.PreStopDelay.Set(value)
+	case "drain_timeout":
+		return st.DrainTimeout.Set(value)
+	case "tls_cert_file":
+		st.TLSCertFile = value
+	case "tls_key_file":
+		st.TLSKeyFile = value
+	case "tls_client_ca_file":
+		st.TLSClientCA = value
+	default:
+		return ErrUnknownSetting
+	}
+	return nil
+}
+
+// Merge overrides the settings of st with the ones set in o
+func (st *Settings) Merge(o Settings) {
+	for _, f := range []struct {
+		dst *string
+		v   string
+	}{
+		{&st.Description, o.Description},
+		{&st.Addr, o.Addr},
+		{&st.TLSCertFile, o.TLSCertFile},
+		{&st.TLSKeyFile, o.TLSKeyFile},
+		{&st.TLSClientCA, o.TLSClientCA},
+	} {
+		if f.v != "" {
+			*f.dst = f.v
+		}
+	}
+	for _, d := range []struct {
+		dst *common.Duration
+		v   common.Duration
+	}{
+		{&st.ReadTimeout, o.ReadTimeout},
+		{&st.WriteTimeout, o.WriteTimeout},
+		{&st.PreStopDelay, o.PreStopDelay},
+		{&st.DrainTimeout, o.DrainTimeout},
+	} {
+		if d.v != 0 {
+			*d.dst = d.v
+		}
+	}
+}
+
+// Validate returns an error describing every invalid setting
+func (st *Settings) Validate() []error {
+	var errs []error
+	if st.Addr != "" {
+		if _, err := common.Port(st.Addr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, d := range []struct {
+		key string
+		v   common.Duration
+	}{
+		{"read_timeout", st.ReadTimeout},
+		{"write_timeout", st.WriteTimeout},
+		{"pre_stop_delay", st.PreStopDelay},
+		{"drain_timeout", st.DrainTimeout},
+	} {
+		if d.v < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", d.key))
+		}
+	}
+	if (st.TLSCertFile == "") != (st.TLSKeyFile == "") {
+		errs = append(errs, errors.New("tls_cert_file and tls_key_file must be set together"))
+	}
+	if st.TLSClientCA != "" && st.TLSCertFile == "" {
+		errs = append(errs, errors.New("tls_client_ca_file requires tls_cert_file and tls_key_file"))
+	}
+	for _, f := range []string{st.TLSCertFile, st.TLSKeyFile, st.TLSClientCA} {
+		if f == "" {
+			continue
+		}
+		if _, err := os.Stat(f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Options returns the server options equivalent to the settings
+func (st *Settings) Options(name string) []Option {
+	opts := []Option{Name(name)}
+	if st.Description != "" {
+		opts = append(opts, Description(st.Description))
+	}
+	if st.Addr != "" {
+		opts = append(opts, Addr(st.Addr))
+	}
+	for _, d := range []struct {
+		v   common.Duration
+		opt func(time.Duration) Option
+	}{
+		{st.ReadTimeout, ReadTimeout},
+		{st.WriteTimeout, WriteTimeout},
+		{st.PreStopDelay, PreStopDelay},
+		{st.DrainTimeout, DrainTimeout},
+	} {
+		if d.v != 0 {
+			opts = append(opts, d.opt(d.v.Duration()))
+		}
+	}
+	if st.TLSCertFile != "" {
+		opts = append(opts, TLSConfig(st.TLSCertFile, st.TLSKeyFile))
+	}
+	if st.TLSClientCA != "" {
+		opts = append(opts, ClientCAs(st.TLSClientCA))
+	}
+	return opts
+}
@@ -0,0 +1,311 @@
+package server_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/paulormart/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/aukbit/pluto/v6/certificate"
+	"github.com/aukbit/pluto/v6/client"
+	"github.com/aukbit/pluto/v6/reply"
+	"github.com/aukbit/pluto/v6/server"
+	"github.com/aukbit/pluto/v6/server/router"
+	pb "github.com/aukbit/pluto/v6/test/proto"
+)
+
+// writeCert creates a key pair signed by parent, or self signed if nil, and
+// writes it as PEM files in dir
+func writeCert(t *testing.T, dir, name string, tmpl *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kder, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for file, block := range map[string]*pem.Block{
+		name + ".crt": {Type: "CERTIFICATE", Bytes: der},
+		name + ".key": {Type: "EC PRIVATE KEY", Bytes: kder},
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cert, key
+}
+
+// writeCerts writes a certificate authority and the server and gopher
+// client key pairs it signed
+func writeCerts(t *testing.T) string {
+	dir := t.TempDir()
+	now := time.Now()
+	ca, caKey := writeCert(t, dir, "ca", &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "pluto ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	writeCert(t, dir, "server", &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		// also valid for client authentication, as grpc servers present it
+		// to check their own health
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+	spiffe, _ := url.Parse("spiffe://aukbit.com/gopher")
+	writeCert(t, dir, "gopher", &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "gopher", Organization: []string{"aukbit"}},
+		URIs:         []*url.URL{spiffe},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+	return dir
+}
+
+type identityGreeter struct {
+	pb.UnimplementedGreeterServer
+}
+
+// SayHello greets the verified client
+func (s *identityGreeter) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	id, ok := server.PeerIdentityFromContext(ctx)
+	if !ok {
+		return &pb.HelloReply{Message: "Hello stranger"}, nil
+	}
+	return &pb.HelloReply{Message: fmt.Sprintf("Hello %s %v", id.CommonName, id.URIs)}, nil
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := writeCerts(t)
+	file := func(name string) string { return filepath.Join(dir, name) }
+	s := server.New(
+		server.Name("mtls"),
+		server.Addr(":8091"),
+		server.GRPCRegister(func(g *grpc.Server) {
+			pb.RegisterGreeterServer(g, &identityGreeter{})
+		}),
+		server.TLSConfig(file("server.crt"), file("server.key")),
+		server.ClientCAs(file("ca.crt")),
+	)
+	assert.Equal(t, nil, s.Validate())
+	go s.Run()
+	defer s.Stop()
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, "SERVING", s.Health().Status.String())
+
+	register := client.GRPCRegister(func(cc *grpc.ClientConn) interface{} {
+		return pb.NewGreeterClient(cc)
+	})
+	// verified client
+	c := client.New(
+		client.Target("127.0.0.1:8091"),
+		register,
+		client.RootCAs(file("ca.crt")),
+		client.Certificate(file("gopher.crt"), file("gopher.key")),
+		client.ServerName("localhost"),
+	)
+	assert.Equal(t, nil, c.Validate())
+	c.Init()
+	defer c.Close()
+	greeter, err := client.StubOf[pb.GreeterClient](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := greeter.SayHello(context.Background(), &pb.HelloRequest{Name: "Gopher"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Hello gopher [spiffe://aukbit.com/gopher]", res.Message)
+
+	// client without certificate
+	anon := client.New(
+		client.Target("localhost:8091"),
+		register,
+		client.RootCAs(file("ca.crt")),
+	)
+	defer anon.Close()
+	greeter, err = client.StubOf[pb.GreeterClient](anon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = greeter.SayHello(context.Background(), &pb.HelloRequest{Name: "Gopher"})
+	assert.Equal(t, true, err != nil)
+
+	// client not trusting the server
+	untrusted := client.New(client.Target("localhost:8091"), register, client.RootCAs())
+	defer untrusted.Close()
+	greeter, err = client.StubOf[pb.GreeterClient](untrusted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = greeter.SayHello(context.Background(), &pb.HelloRequest{Name: "Gopher"})
+	assert.Equal(t, true, err != nil)
+}
+
+func TestMutualTLSHttps(t *testing.T) {
+	dir := writeCerts(t)
+	file := func(name string) string { return filepath.Join(dir, name) }
+	mux := router.New()
+	mux.GET("/whoami", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := server.PeerIdentityFromContext(r.Context())
+		reply.Json(w, r, http.StatusOK, id)
+	})
+	s := server.New(
+		server.Name("mtls_https"),
+		server.Addr(":8092"),
+		server.Mux(mux),
+		server.TLSConfig(file("server.crt"), file("server.key")),
+		server.ClientCAs(file("ca.crt")),
+		server.ClientAuth(tls.VerifyClientCertIfGiven),
+	)
+	go s.Run()
+	defer s.Stop()
+	time.Sleep(time.Millisecond * 100)
+
+	ca, err := ioutil.ReadFile(file("ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca)
+	cer, err := tls.LoadX509KeyPair(file("gopher.crt"), file("gopher.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		Certificates []tls.Certificate
+		Subject      string
+	}{
+		{Certificates: []tls.Certificate{cer}, Subject: "CN=gopher,O=aukbit"},
+		// certificates are optional with VerifyClientCertIfGiven
+		{Certificates: nil, Subject: ""},
+	} {
+		c := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      pool,
+			Certificates: test.Certificates,
+		}}}
+		r, err := c.Get("https://localhost:8092/whoami")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var id server.PeerIdentity
+		if err := json.NewDecoder(r.Body).Decode(&id); err != nil {
+			t.Fatal(err)
+		}
+		r.Body.Close()
+		assert.Equal(t, http.StatusOK, r.StatusCode)
+		assert.Equal(t, test.Subject, id.Subject)
+	}
+}
+
+func TestCertificateSource(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	serverCert := func(serial int64, d time.Duration) *x509.Certificate {
+		return &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "localhost"},
+			DNSNames:     []string{"localhost"},
+			NotBefore:    now.Add(-time.Hour),
+			NotAfter:     now.Add(d),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}
+	}
+	ca, caKey := writeCert(t, dir, "ca", &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "pluto ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(72 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	writeCert(t, dir, "server", serverCert(2, 48*time.Hour), ca, caKey)
+	src, err := certificate.NewSource(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := router.New()
+	s := server.New(
+		server.Name("rotating"),
+		server.Addr(":8093"),
+		server.Mux(mux),
+		server.CertificateSource(src),
+	)
+	go s.Run()
+	defer s.Stop()
+	time.Sleep(time.Millisecond * 100)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	c := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pool},
+		DisableKeepAlives: true,
+	}}
+	get := func() (int64, map[string]interface{}) {
+		r, err := c.Get("https://localhost:8093/_health")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Body.Close()
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return r.TLS.PeerCertificates[0].SerialNumber.Int64(), body["certificate"].(map[string]interface{})
+	}
+	serial, st := get()
+	assert.Equal(t, int64(2), serial)
+	assert.Equal(t, false, st["expiring"])
+	assert.Equal(t, "SERVING", s.Health().Status.String())
+
+	// rotated without restart, the new certificate expires within a day
+	writeCert(t, dir, "server", serverCert(3, time.Hour), ca, caKey)
+	assert.Equal(t, nil, src.Reload())
+	serial, st = get()
+	assert.Equal(t, int64(3), serial)
+	assert.Equal(t, true, st["expiring"])
+	assert.Equal(t, "SERVING", s.Health().Status.String())
+}
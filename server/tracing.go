@@ -0,0 +1,92 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/aukbit/pluto/v6/trace"
+)
+
+// tracingMiddleware Middleware that starts a server span for the route
+// matched, child of the traceparent header if any
+func tracingMiddleware(s *Server) router.Middleware {
+	return func(h router.HandlerFunc) router.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/_health", "/healthz/ready", "/healthz/live", "/metrics":
+				h.ServeHTTP(w, r)
+			default:
+				ctx := trace.Extract(r.Context(), r.Header)
+				route := router.PatternFromContext(ctx)
+				ctx, span := s.cfg.Tracer.Start(ctx, r.Method+" "+route, trace.SpanKindServer)
+				defer span.End()
+				span.SetAttribute("http.method", r.Method)
+				span.SetAttribute("http.route", route)
+				span.SetAttribute("pluto.server", s.Name())
+				sw := newStatusWriter(w)
+				h.ServeHTTP(sw, r.WithContext(ctx))
+				span.SetAttribute("http.status_code", strconv.Itoa(sw.status))
+				if sw.status >= http.StatusInternalServerError {
+					span.SetStatus(trace.StatusError, http.StatusText(sw.status))
+				}
+			}
+		}
+	}
+}
+
+// tracingUnaryServerInterceptor starts a server span for the grpc method,
+// child of the traceparent in incoming metadata if any
+func tracingUnaryServerInterceptor(s *Server) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod == "/grpc.health.v1.Health/Check" {
+			return handler(ctx, req)
+		}
+		ctx, span := s.startGRPCSpan(ctx, info.FullMethod)
+		defer span.End()
+		h, err := handler(ctx, req)
+		endGRPCSpan(span, err)
+		return h, err
+	}
+}
+
+// tracingStreamServerInterceptor starts a server span lasting the whole
+// stream, child of the traceparent in incoming metadata if any
+func tracingStreamServerInterceptor(s *Server) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := s.startGRPCSpan(ss.Context(), info.FullMethod)
+		defer span.End()
+		// wrap context
+		wrapped := WrapServerStreamWithContext(ss)
+		wrapped.SetContext(ctx)
+		err := handler(srv, wrapped)
+		endGRPCSpan(span, err)
+		return err
+	}
+}
+
+// --- Helper functions
+
+func (s *Server) startGRPCSpan(ctx context.Context, method string) (context.Context, *trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = trace.ExtractMetadata(ctx, md)
+	}
+	ctx, span := s.cfg.Tracer.Start(ctx, method, trace.SpanKindServer)
+	span.SetAttribute("rpc.system", "grpc")
+	span.SetAttribute("rpc.method", method)
+	span.SetAttribute("pluto.server", s.Name())
+	return ctx, span
+}
+
+func endGRPCSpan(span *trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttribute("rpc.grpc.status_code", code.String())
+	if err != nil {
+		span.SetStatus(trace.StatusError, err.Error())
+	}
+}
@@ -0,0 +1,361 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/aukbit/pluto/v6/reply"
+	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/aukbit/pluto/v6/trace"
+)
+
+// binding is an http route bound to a unary grpc method
+type binding struct {
+	method     string   // http method
+	path       string   // router path, e.g. /v1/rooms/:name
+	params     []string // request fields bound from the path
+	body       string   // request field bound from the body, * for all
+	fullMethod string   // grpc method, e.g. /helloworld.Greeter/SayHello
+	in, out    reflect.Type
+}
+
+// setTranscoding adds to the router an http route for every unary method of
+// the grpc services registered, as annotated with google.api.http or, if
+// not annotated, POST /package.Service/Method with the request as body,
+// described with the request and response messages of the method.
+// Calls are made to the grpc server in-process.
+func (s *Server) setTranscoding() {
+	if s.cfg.Mux == nil {
+		s.cfg.Mux = router.New()
+	}
+	for _, b := range s.bindings() {
+		s.cfg.Mux.HandleFunc(b.method, b.path, s.transcode(b))
+		s.cfg.Mux.Describe(b.method, b.path, router.Metadata{
+			Summary:  b.fullMethod,
+			Request:  reflect.New(b.in.Elem()).Interface(),
+			Response: reflect.New(b.out.Elem()).Interface(),
+		})
+	}
+	s.inproc = bufconn.Listen(1 << 20)
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return s.inproc.Dial()
+		}),
+	)
+	if err != nil {
+		s.logger.Error().Msg(err.Error())
+		return
+	}
+	s.transcoder = conn
+}
+
+// bindings returns the http bindings of the grpc services registered
+func (s *Server) bindings() []binding {
+	var bindings []binding
+	info := s.grpcServer.GetServiceInfo()
+	names := make([]string, 0, len(info))
+	for name := range info {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == "grpc.health.v1.Health" {
+			continue
+		}
+		sd, err := serviceDescriptor(name, info[name].Metadata)
+		if err != nil {
+			s.logger.Warn().Msg(fmt.Sprintf("%s: transcoding %s skipped, %v", s.Name(), name, err))
+			continue
+		}
+		for _, md := range sd.GetMethod() {
+			if md.GetClientStreaming() || md.GetServerStreaming() {
+				continue
+			}
+			fullMethod := "/" + name + "/" + md.GetName()
+			in := proto.MessageType(strings.TrimPrefix(md.GetInputType(), "."))
+			out := proto.MessageType(strings.TrimPrefix(md.GetOutputType(), "."))
+			if in == nil || out == nil {
+				s.logger.Warn().Msg(fmt.Sprintf("%s: transcoding %s skipped, message types not registered", s.Name(), fullMethod))
+				continue
+			}
+			for _, rule := range httpRules(md, fullMethod) {
+				b, err := newBinding(rule)
+				if err != nil {
+					s.logger.Warn().Msg(fmt.Sprintf("%s: transcoding %s skipped, %v", s.Name(), fullMethod, err))
+					continue
+				}
+				b.fullMethod, b.in, b.out = fullMethod, in, out
+				bindings = append(bindings, b)
+			}
+		}
+	}
+	return bindings
+}
+
+// transcode returns the handler calling the grpc method of b with the
+// request message bound from the http request
+func (s *Server) transcode(b binding) router.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in := reflect.New(b.in.Elem()).Interface().(proto.Message)
+		if err := b.bind(r, in); err != nil {
+			reply.Json(w, r, http.StatusBadRequest, &router.Err{
+				Type:    "invalid_request_error",
+				Message: err.Error(),
+			})
+			return
+		}
+		if s.transcoder == nil {
+			reply.Json(w, r, http.StatusServiceUnavailable, &router.Err{
+				Type:    "api_error",
+				Message: "transcoding not available",
+			})
+			return
+		}
+		out := reflect.New(b.out.Elem()).Interface().(proto.Message)
+		if err := s.transcoder.Invoke(outgoingContext(r), b.fullMethod, in, out); err != nil {
+			st := status.Convert(err)
+			reply.Json(w, r, HTTPStatus(st.Code()), &router.Err{
+				Type:    "api_error",
+				Message: st.Message(),
+				Code:    st.Code().String(),
+			})
+			return
+		}
+		reply.Jsonpb(w, r, http.StatusOK, &jsonpb.Marshaler{}, out)
+	}
+}
+
+// bind sets in from the body, path params and query parameters of r
+func (b binding) bind(r *http.Request, in proto.Message) error {
+	switch b.body {
+	case "":
+	case "*":
+		if err := jsonpb.Unmarshal(r.Body, in); err != nil && err != io.EOF {
+			return err
+		}
+	default:
+		raw, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(raw)) > 0 {
+			if err := mergeJSON(in, b.body, raw); err != nil {
+				return err
+			}
+		}
+	}
+	ctx := r.Context()
+	bound := map[string]bool{}
+	for _, p := range b.params {
+		bound[p] = true
+		if err := setField(in, p, router.FromContextParam(ctx, p)); err != nil {
+			return err
+		}
+	}
+	if b.body == "*" {
+		return nil
+	}
+	for key, values := range r.URL.Query() {
+		if bound[key] || key == b.body {
+			continue
+		}
+		if err := setField(in, key, values...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HTTPStatus returns the http status corresponding to the grpc code c
+func HTTPStatus(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // client closed request
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// --- Helper functions
+
+// serviceDescriptor returns the descriptor of the service name, looked up in
+// the proto file registered under meta, the grpc.ServiceDesc Metadata
+func serviceDescriptor(name string, meta interface{}) (*descriptor.ServiceDescriptorProto, error) {
+	file, ok := meta.(string)
+	if !ok {
+		return nil, fmt.Errorf("proto file unknown")
+	}
+	gz := proto.FileDescriptor(file)
+	if gz == nil {
+		return nil, fmt.Errorf("proto file %s not registered", file)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	fd := &descriptor.FileDescriptorProto{}
+	if err := proto.Unmarshal(raw, fd); err != nil {
+		return nil, err
+	}
+	for _, sd := range fd.GetService() {
+		if fd.GetPackage()+"."+sd.GetName() == name || sd.GetName() == name {
+			return sd, nil
+		}
+	}
+	return nil, fmt.Errorf("service not found in proto file %s", file)
+}
+
+// httpRules returns the google.api.http rules of md, including additional
+// bindings, or the default POST /package.Service/Method rule
+func httpRules(md *descriptor.MethodDescriptorProto, fullMethod string) []*annotations.HttpRule {
+	if md.GetOptions() != nil {
+		if ext, err := proto.GetExtension(md.GetOptions(), annotations.E_Http); err == nil {
+			if rule, ok := ext.(*annotations.HttpRule); ok {
+				return append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...)
+			}
+		}
+	}
+	return []*annotations.HttpRule{{
+		Pattern: &annotations.HttpRule_Post{Post: fullMethod},
+		Body:    "*",
+	}}
+}
+
+// newBinding converts the path template of rule, e.g. /v1/rooms/{name},
+// into a router path, e.g. /v1/rooms/:name
+func newBinding(rule *annotations.HttpRule) (binding, error) {
+	b := binding{body: rule.GetBody()}
+	var template string
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		b.method, template = http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		b.method, template = http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		b.method, template = http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		b.method, template = http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		b.method, template = http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		b.method, template = strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	default:
+		return b, fmt.Errorf("http rule without pattern")
+	}
+	if !strings.HasPrefix(template, "/") {
+		return b, fmt.Errorf("path template %q must start with /", template)
+	}
+	segments := strings.Split(template[1:], "/")
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, "{") {
+			if strings.Contains(seg, "*") || strings.ContainsAny(seg, "{}") {
+				return b, fmt.Errorf("path template %q not supported", template)
+			}
+			continue
+		}
+		field := strings.TrimSuffix(strings.TrimPrefix(seg, "{"), "}")
+		field = strings.TrimSuffix(field, "=*")
+		if field == "" || strings.ContainsAny(field, "{}=*/") {
+			return b, fmt.Errorf("path template %q not supported", template)
+		}
+		b.params = append(b.params, field)
+		segments[i] = ":" + field
+	}
+	b.path = "/" + strings.Join(segments, "/")
+	return b, nil
+}
+
+// setField sets the field at path, e.g. room.name, of in to values, decoded
+// as json strings or, failing that, as json literals such as numbers or
+// bools. A single value may also set a repeated field.
+func setField(in proto.Message, path string, values ...string) error {
+	quoted, _ := json.Marshal(values)
+	candidates := [][]byte{quoted, []byte("[" + strings.Join(values, ",") + "]")}
+	if len(values) == 1 {
+		quoted, _ := json.Marshal(values[0])
+		candidates = append([][]byte{quoted, []byte(values[0])}, candidates...)
+	}
+	var err error
+	for _, raw := range candidates {
+		if err = mergeJSON(in, path, raw); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value for field %s: %v", path, err)
+}
+
+// mergeJSON merges into in the json value raw of the field at path
+func mergeJSON(in proto.Message, path string, raw []byte) error {
+	fields := strings.Split(path, ".")
+	var buf bytes.Buffer
+	for _, f := range fields {
+		k, _ := json.Marshal(f)
+		buf.WriteString("{" + string(k) + ":")
+	}
+	buf.Write(raw)
+	buf.WriteString(strings.Repeat("}", len(fields)))
+	m := reflect.New(reflect.TypeOf(in).Elem()).Interface().(proto.Message)
+	if err := jsonpb.Unmarshal(&buf, m); err != nil {
+		return err
+	}
+	proto.Merge(in, m)
+	return nil
+}
+
+// outgoingContext returns the context of r with the eid, trace and
+// authorization of the http request in outgoing grpc metadata
+func outgoingContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		md.Set("authorization", auth)
+	}
+	trace.InjectMetadata(ctx, md)
+	return metadata.NewOutgoingContext(ctx, md)
+}
@@ -0,0 +1,225 @@
+package server_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/paulormart/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+
+	"github.com/aukbit/pluto/v6/server"
+	pb "github.com/aukbit/pluto/v6/test/proto"
+)
+
+// rooms.Rooms service, annotated with google.api.http and registered by
+// hand as there is no generated code for it
+func init() {
+	opts := &descriptor.MethodOptions{}
+	rule := &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/rooms/{name}"},
+		AdditionalBindings: []*annotations.HttpRule{{
+			Pattern: &annotations.HttpRule_Get{Get: "/rooms"},
+		}},
+	}
+	if err := proto.SetExtension(opts, annotations.E_Http, rule); err != nil {
+		panic(err)
+	}
+	fd := &descriptor.FileDescriptorProto{
+		Name:    proto.String("rooms.proto"),
+		Package: proto.String("rooms"),
+		Service: []*descriptor.ServiceDescriptorProto{{
+			Name: proto.String("Rooms"),
+			Method: []*descriptor.MethodDescriptorProto{{
+				Name:       proto.String("Get"),
+				InputType:  proto.String(".helloworld.HelloRequest"),
+				OutputType: proto.String(".helloworld.HelloReply"),
+				Options:    opts,
+			}},
+		}},
+	}
+	b, err := proto.Marshal(fd)
+	if err != nil {
+		panic(err)
+	}
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write(b)
+	w.Close()
+	proto.RegisterFile("rooms.proto", gz.Bytes())
+}
+
+var roomsServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rooms.Rooms",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Get",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(pb.HelloRequest)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return &pb.HelloReply{Message: "Room " + req.(*pb.HelloRequest).Name}, nil
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/rooms.Rooms/Get"}, handler)
+		},
+	}},
+	Metadata: "rooms.proto",
+}
+
+func TestTranscoding(t *testing.T) {
+	s := server.New(
+		server.Name("transcoding"),
+		server.Addr(":8089"),
+		server.GRPCRegister(func(g *grpc.Server) {
+			pb.RegisterGreeterServer(g, &greeter{})
+			g.RegisterService(&roomsServiceDesc, struct{}{})
+		}),
+		server.Combined(),
+		server.Transcoding(),
+	)
+	go s.Run()
+	defer s.Stop()
+	time.Sleep(time.Millisecond * 100)
+
+	var tests = []struct {
+		Method string
+		Path   string
+		Body   string
+		Status int
+		Reply  map[string]string
+	}{
+		// default mapping
+		{
+			Method: "POST",
+			Path:   "/helloworld.Greeter/SayHello",
+			Body:   `{"name":"Gopher"}`,
+			Status: http.StatusOK,
+			Reply:  map[string]string{"message": "Hello Gopher"},
+		},
+		// grpc status mapped to http status
+		{
+			Method: "POST",
+			Path:   "/helloworld.Greeter/SayGoodbye",
+			Body:   `{"name":"Gopher"}`,
+			Status: http.StatusNotImplemented,
+			Reply:  map[string]string{"code": "Unimplemented"},
+		},
+		{
+			Method: "POST",
+			Path:   "/helloworld.Greeter/SayHello",
+			Body:   `{"unknown":"Gopher"}`,
+			Status: http.StatusBadRequest,
+			Reply:  map[string]string{"type": "invalid_request_error"},
+		},
+		// annotated with path binding
+		{
+			Method: "GET",
+			Path:   "/rooms/lobby",
+			Status: http.StatusOK,
+			Reply:  map[string]string{"message": "Room lobby"},
+		},
+		// additional binding with query binding
+		{
+			Method: "GET",
+			Path:   "/rooms?name=kitchen",
+			Status: http.StatusOK,
+			Reply:  map[string]string{"message": "Room kitchen"},
+		},
+	}
+	for _, test := range tests {
+		req, err := http.NewRequest(test.Method, "http://localhost:8089"+test.Path, strings.NewReader(test.Body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		r, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		r.Body.Close()
+		assert.Equal(t, test.Status, r.StatusCode)
+		for k, v := range test.Reply {
+			assert.Equal(t, v, body[k])
+		}
+	}
+}
+
+func TestRoutesEndpoint(t *testing.T) {
+	s := server.New(
+		server.Name("routes"),
+		server.Addr(":8102"),
+		server.GRPCRegister(func(g *grpc.Server) {
+			g.RegisterService(&roomsServiceDesc, struct{}{})
+		}),
+		server.Combined(),
+		server.Transcoding(),
+		server.RoutesEndpoint(),
+	)
+	go s.Run()
+	defer s.Stop()
+	time.Sleep(time.Millisecond * 100)
+
+	r, err := http.Get("http://localhost:8102/_routes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	var routes []struct {
+		Method   string
+		Path     string
+		Metadata struct{ Summary string }
+	}
+	if err := json.NewDecoder(r.Body).Decode(&routes); err != nil {
+		t.Fatal(err)
+	}
+	summaries := make(map[string]string)
+	for _, route := range routes {
+		summaries[route.Method+" "+route.Path] = route.Metadata.Summary
+	}
+	assert.Equal(t, "/rooms.Rooms/Get", summaries["GET /rooms/:name"])
+	assert.Equal(t, "/rooms.Rooms/Get", summaries["GET /rooms"])
+	assert.Equal(t, "", summaries["GET /_health"])
+
+	r, err = http.Get("http://localhost:8102/_routes/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	var doc struct {
+		Info  struct{ Title string }
+		Paths map[string]map[string]struct {
+			Responses map[string]struct {
+				Content map[string]struct {
+					Schema struct {
+						Ref string `json:"$ref"`
+					}
+				}
+			}
+		}
+	}
+	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "routes_server", doc.Info.Title)
+	get := doc.Paths["/rooms/{name}"]["get"]
+	assert.Equal(t, "#/components/schemas/helloworld.HelloReply", get.Responses["200"].Content["application/json"].Schema.Ref)
+}
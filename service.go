@@ -17,27 +17,37 @@ import (
 	"github.com/aukbit/pluto/v6/common"
 	"github.com/aukbit/pluto/v6/server"
 	"github.com/aukbit/pluto/v6/server/router"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 const (
-	defaultName       = "pluto"
-	defaultHealthAddr = ":9090"
+	defaultName            = "pluto"
+	defaultHealthAddr      = ":9090"
+	defaultShutdownTimeout = 30 * time.Second
 )
 
 var (
 	ErrDatastoreNotInitialized = errors.New("datastore not initialized")
+	errTokenNotAvailable       = errors.New("token not available in context")
 )
 
+// exit terminates the process when shutdown exceeds its deadline
+var exit = os.Exit
+
 // Service representacion of a pluto service
 type Service struct {
-	close  chan bool
+	ctx    context.Context    // root context, cancelled when the service stops
+	cancel context.CancelFunc // stops the service
 	cfg    Config
 	wg     *sync.WaitGroup
 	health *health.Server
 	logger zerolog.Logger
+	errs   common.Errors  // errors recorded while applying options
+	hsrv   *server.Server // health server
 }
 
 func init() {
@@ -62,10 +72,10 @@ func New(opts ...Option) *Service {
 func newService(opts ...Option) *Service {
 	s := &Service{
 		cfg:    newConfig(),
-		close:  make(chan bool),
 		wg:     &sync.WaitGroup{},
 		health: health.NewServer(),
 	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
 	s.logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
 	if len(opts) > 0 {
 		s = s.WithOptions(opts...)
@@ -76,6 +86,8 @@ func newService(opts ...Option) *Service {
 // WithOptions clones the current Service, applies the supplied Options, and
 // returns the resulting Service. It's safe to use concurrently.
 func (s *Service) WithOptions(opts ...Option) *Service {
+	s.cfg.mu.Lock()
+	defer s.cfg.mu.Unlock()
 	for _, opt := range opts {
 		opt.apply(s)
 	}
@@ -85,14 +97,26 @@ func (s *Service) WithOptions(opts ...Option) *Service {
 // Run starts service
 func (s *Service) Run() error {
 	s.logger = s.logger.With().Str("id", s.cfg.ID).Str("name", s.cfg.Name).Logger()
+	// do not start a half configured service
+	if err := s.Validate(); err != nil {
+		return err
+	}
 	// set health server
 	s.setHealthServer()
+	// hook run before start
+	if err := s.runHooks(s.ctx, hookBeforeStart); err != nil {
+		return err
+	}
 	// start service
 	if err := s.start(); err != nil {
 		return err
 	}
+	// register at service discovery
+	if err := s.register(); err != nil {
+		s.logger.Error().Msg(err.Error())
+	}
 	// hook run after start
-	if err := s.hookAfterStart(); err != nil {
+	if err := s.runHooks(s.ctx, hookAfterStart); err != nil {
 		return err
 	}
 	// wait for all go routines to finish
@@ -101,35 +125,202 @@ func (s *Service) Run() error {
 	return nil
 }
 
-// Stop stops service
+// Validate returns, aggregated, the errors recorded by options together with
+// the validation errors of every server and client and any port collision
+// between servers, including the health server
+func (s *Service) Validate() error {
+	s.cfg.mu.RLock()
+	defer s.cfg.mu.RUnlock()
+	errs := append(common.Errors{}, s.errs...)
+	if _, err := common.Port(s.cfg.HealthAddr); err != nil {
+		errs = append(errs, fmt.Errorf("health: %v", err))
+	}
+	if s.cfg.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("shutdown timeout must be positive, got %v", s.cfg.ShutdownTimeout))
+	}
+	errs = append(errs, s.validate(s.cfg.Servers, s.cfg.Clients, nil)...)
+	return errs.Err()
+}
+
+// validate returns the validation errors of servers and clients, checking
+// server ports against the health server and the servers in used
+func (s *Service) validate(servers map[string]*server.Server, clients map[string]*client.Client, used map[string]*server.Server) common.Errors {
+	var errs common.Errors
+	ports := make(map[int]string)
+	if p, _ := common.Port(s.cfg.HealthAddr); p != 0 {
+		ports[p] = "health server"
+	}
+	for name, srv := range used {
+		if srv == s.hsrv {
+			continue
+		}
+		if p, _ := common.Port(srv.Addr()); p != 0 {
+			ports[p] = name
+		}
+	}
+	for _, name := range sortedKeys(servers) {
+		srv := servers[name]
+		if srv == s.hsrv {
+			continue
+		}
+		if err := srv.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		p, _ := common.Port(srv.Addr())
+		if other, ok := ports[p]; ok && p != 0 {
+			errs = append(errs, fmt.Errorf("%s: port %d already used by %s", name, p, other))
+			continue
+		}
+		ports[p] = name
+	}
+	for _, name := range sortedKeys(clients) {
+		if err := clients[name].Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Stop stops service by cancelling its root context
 func (s *Service) Stop() {
 	s.logger.Info().Msg(fmt.Sprintf("shutting down %s", s.Name()))
-	s.close <- true
+	s.cancel()
 }
 
-// Push allows to start additional options while service is running
-func (s *Service) Push(opts ...Option) {
+// Push applies additional options to the service. Servers and clients
+// added while the service is running are started and initialized straight
+// away. Options are applied all or nothing: if any of them records an error
+// or adds an invalid server or client, none of the servers and clients are
+// added and the errors are returned.
+func (s *Service) Push(opts ...Option) error {
+	s.cfg.mu.Lock()
+	defer s.cfg.mu.Unlock()
+	servers := make(map[string]*server.Server, len(s.cfg.Servers))
+	for name, srv := range s.cfg.Servers {
+		servers[name] = srv
+	}
+	clients := make(map[string]*client.Client, len(s.cfg.Clients))
+	for name, clt := range s.cfg.Clients {
+		clients[name] = clt
+	}
+	n := len(s.errs)
 	for _, opt := range opts {
 		opt.apply(s)
 	}
+	errs := append(common.Errors{}, s.errs[n:]...)
+	s.errs = s.errs[:n]
+	// validate only what was pushed
+	addedServers := make(map[string]*server.Server)
+	for name, srv := range s.cfg.Servers {
+		if _, ok := servers[name]; !ok {
+			addedServers[name] = srv
+		}
+	}
+	addedClients := make(map[string]*client.Client)
+	for name, clt := range s.cfg.Clients {
+		if _, ok := clients[name]; !ok {
+			addedClients[name] = clt
+		}
+	}
+	errs = append(errs, s.validate(addedServers, addedClients, servers)...)
+	if err := errs.Err(); err != nil {
+		s.cfg.Servers, s.cfg.Clients = servers, clients
+		return err
+	}
+	if !s.cfg.running {
+		return nil
+	}
+	for _, name := range sortedKeys(addedServers) {
+		s.startServer(addedServers[name])
+	}
+	for _, name := range sortedKeys(addedClients) {
+		s.startClient(addedClients[name])
+	}
+	return nil
+}
+
+// RemoveServer removes the server name from the service. If the service is
+// running the server is stopped, which also unregisters it from the service
+// discovery, and RemoveServer waits for it to drain.
+func (s *Service) RemoveServer(name string) error {
+	name = common.SafeName(name, server.DefaultName)
+	s.cfg.mu.Lock()
+	srv, ok := s.cfg.Servers[name]
+	if !ok {
+		s.cfg.mu.Unlock()
+		return fmt.Errorf("server %s not available", name)
+	}
+	if srv == s.hsrv {
+		s.cfg.mu.Unlock()
+		return fmt.Errorf("server %s can not be removed", name)
+	}
+	delete(s.cfg.Servers, name)
+	running := s.cfg.running
+	s.cfg.mu.Unlock()
+	if running {
+		srv.Stop()
+	}
+	return nil
+}
+
+// RemoveClient removes the client name from the service. If the service is
+// running the client connection is closed.
+func (s *Service) RemoveClient(name string) error {
+	name = common.SafeName(name, client.DefaultName)
+	s.cfg.mu.Lock()
+	clt, ok := s.cfg.Clients[name]
+	if !ok {
+		s.cfg.mu.Unlock()
+		return fmt.Errorf("client %s not available", name)
+	}
+	delete(s.cfg.Clients, name)
+	running := s.cfg.running
+	s.cfg.mu.Unlock()
+	if running {
+		return clt.Close()
+	}
+	return nil
 }
 
 // Server returns a server instance by name if initialized in service
 func (s *Service) Server(name string) (srv *server.Server, ok bool) {
 	name = common.SafeName(name, server.DefaultName)
-	if srv, ok = s.cfg.Servers[name]; !ok {
-		return
-	}
-	return srv, true
+	s.cfg.mu.RLock()
+	defer s.cfg.mu.RUnlock()
+	srv, ok = s.cfg.Servers[name]
+	return srv, ok
 }
 
 // Client returns a client instance by name if initialized in service
 func (s *Service) Client(name string) (clt *client.Client, ok bool) {
 	name = common.SafeName(name, client.DefaultName)
-	if clt, ok = s.cfg.Clients[name]; !ok {
-		return
+	s.cfg.mu.RLock()
+	defer s.cfg.mu.RUnlock()
+	clt, ok = s.cfg.Clients[name]
+	return clt, ok
+}
+
+// servers returns the service servers sorted by name
+func (s *Service) servers() []*server.Server {
+	s.cfg.mu.RLock()
+	defer s.cfg.mu.RUnlock()
+	servers := make([]*server.Server, 0, len(s.cfg.Servers))
+	for _, name := range sortedKeys(s.cfg.Servers) {
+		servers = append(servers, s.cfg.Servers[name])
 	}
-	return clt, true
+	return servers
+}
+
+// clients returns the service clients sorted by name
+func (s *Service) clients() []*client.Client {
+	s.cfg.mu.RLock()
+	defer s.cfg.mu.RUnlock()
+	clients := make([]*client.Client, 0, len(s.cfg.Clients))
+	for _, name := range sortedKeys(s.cfg.Clients) {
+		clients = append(clients, s.cfg.Clients[name])
+	}
+	return clients
 }
 
 // Health ...
@@ -157,9 +348,9 @@ func (s *Service) Logger() zerolog.Logger {
 	return s.logger
 }
 
-// WaitUntilFinish returns service name
+// WaitUntilFinish blocks until the service is asked to stop
 func (s *Service) WaitUntilFinish() {
-	<-s.close
+	<-s.ctx.Done()
 }
 
 func (s *Service) setHealthServer() {
@@ -169,6 +360,7 @@ func (s *Service) setHealthServer() {
 	mux.GET("/healthz/ready", readyHealthHandler)
 	mux.GET("/healthz/live", liveHealthHandler)
 	mux.GET("/_health/:module/:name", healthHandler)
+	mux.Handle("GET", "/metrics", promhttp.Handler())
 	// Define server
 	srv := server.New(
 		server.Name(s.cfg.Name+"_health"),
@@ -176,34 +368,46 @@ func (s *Service) setHealthServer() {
 		server.Mux(mux),
 		// server.Logger(s.logger),
 	)
+	s.hsrv = srv
+	s.cfg.mu.Lock()
 	s.cfg.Servers[srv.Name()] = srv
+	s.cfg.mu.Unlock()
 }
 
 func (s *Service) start() error {
+	// from now on servers and clients pushed are started straight away
+	s.cfg.mu.Lock()
+	defer s.cfg.mu.Unlock()
+	s.cfg.running = true
 	s.logger.Info().Str("ip4", common.IPaddress()).
 		Int("servers", len(s.cfg.Servers)).
 		Int("clients", len(s.cfg.Clients)).
 		Msg(fmt.Sprintf("starting %s, servers: %d clients: %d", s.Name(), len(s.cfg.Servers), len(s.cfg.Clients)))
 	// run servers
-	s.startServers()
+	for _, name := range sortedKeys(s.cfg.Servers) {
+		s.startServer(s.cfg.Servers[name])
+	}
 	// dial clients
-	s.startClients()
+	for _, name := range sortedKeys(s.cfg.Clients) {
+		s.startClient(s.cfg.Clients[name])
+	}
 	// add go routine to WaitGroup
 	s.wg.Add(1)
 	go s.waitUntilStopOrSig()
 	return nil
 }
 
-func (s *Service) hookAfterStart() error {
-	hooks, ok := s.cfg.Hooks["after_start"]
+// runHooks runs in order the hook functions registered under name,
+// stopping at the first error
+func (s *Service) runHooks(ctx context.Context, name string) error {
+	hooks, ok := s.cfg.Hooks[name]
 	if !ok {
 		return nil
 	}
-	ctx := context.Background()
 	// make service available in hooks context
 	ctx = s.WithContext(ctx)
 	// make logger available in hooks context
-	sublogger := s.logger.With().Str("hook", "after_start").Logger()
+	sublogger := s.logger.With().Str("hook", name).Logger()
 	ctx = sublogger.WithContext(ctx)
 	for _, h := range hooks {
 		if err := h(ctx); err != nil {
@@ -213,174 +417,229 @@ func (s *Service) hookAfterStart() error {
 	return nil
 }
 
-func (s *Service) startServers() {
-	for _, srv := range s.cfg.Servers {
-		// add go routine to WaitGroup
-		s.wg.Add(1)
-		go func(s *Service, srv *server.Server) {
-			defer s.wg.Done()
-			f := fibonacci.F()
-			for {
-				err := srv.Run(
-					server.Middlewares(
-						serviceContextMiddleware(s),
-					),
-					server.UnaryServerInterceptors(
-						serviceContextUnaryServerInterceptor(s),
-					),
-					server.StreamServerInterceptors(
-						serviceContextStreamServerInterceptore(s),
-					),
-					server.Logger(s.logger),
-				)
-				if err == nil {
-					return
-				}
-				l := srv.Logger()
-				l.Error().Msg(fmt.Sprintf("%v failed to start, error: %v", srv.Name(), err.Error()))
-				time.Sleep(time.Duration(f()) * time.Second)
-			}
-		}(s, srv)
-	}
-}
-
-// startClients listen to the clientsCh
-func (s *Service) startClients() {
-	go func() {
+// startServer runs srv, retrying until it starts or the service stops
+func (s *Service) startServer(srv *server.Server) {
+	// add go routine to WaitGroup
+	s.wg.Add(1)
+	go func(s *Service, srv *server.Server) {
+		defer s.wg.Done()
+		opts := []server.Option{
+			server.Middlewares(
+				serviceContextMiddleware(s),
+			),
+			server.UnaryServerInterceptors(
+				serviceContextUnaryServerInterceptor(s),
+			),
+			server.StreamServerInterceptors(
+				serviceContextStreamServerInterceptore(s),
+			),
+			server.Logger(s.logger),
+		}
+		if s.cfg.Tracer != nil {
+			opts = append(opts, server.Tracer(s.cfg.Tracer))
+		}
+		// register servers at the service discovery, checked through
+		// the service health server
+		if s.cfg.Discovery != nil && srv != s.hsrv {
+			opts = append(opts,
+				server.Discovery(s.cfg.Discovery),
+				server.HealthCheckURL(s.healthURL("server", srv.Name())),
+			)
+		}
+		f := fibonacci.F()
 		for {
+			err := srv.Run(opts...)
+			if err == nil {
+				return
+			}
+			l := srv.Logger()
+			l.Error().Msg(fmt.Sprintf("%v failed to start, error: %v", srv.Name(), err.Error()))
+			// retry with fibonacci backoff until the service stops or the
+			// server is removed
 			select {
-			case clt, ok := <-s.cfg.clientsCh:
-				if !ok {
-					break
-				}
-				s.startClient(clt)
-			default:
-				time.Sleep(500 * time.Millisecond)
-				continue
+			case <-s.ctx.Done():
+				return
+			case <-time.After(time.Duration(f()) * time.Second):
+			}
+			s.cfg.mu.RLock()
+			_, ok := s.cfg.Servers[srv.Name()]
+			s.cfg.mu.RUnlock()
+			if !ok {
+				return
 			}
 		}
-	}()
+	}(s, srv)
 }
 
+// startClient initializes clt with the service logger and tracer
 func (s *Service) startClient(clt *client.Client) {
-	go func(clt *client.Client) {
-		clt.Init(client.Logger(s.logger))
-	}(clt)
+	opts := []client.Option{client.Logger(s.logger)}
+	if s.cfg.Tracer != nil {
+		opts = append(opts, client.Tracer(s.cfg.Tracer))
+	}
+	clt.Init(opts...)
 }
 
-// waitUntilStopOrSig waits for close channel or syscall Signal
+// waitUntilStopOrSig waits for the root context to be cancelled or a syscall
+// Signal, in which case it cancels the root context itself
 func (s *Service) waitUntilStopOrSig() {
 	defer s.wg.Done()
 	//  Stop also in case of any host signal
 	sigch := make(chan os.Signal, 1)
 	signal.Notify(sigch, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sigch)
+
+	select {
+	case <-s.ctx.Done():
+		// Waits for call to stop
+	case sig := <-sigch:
+		// Waits for signal to stop
+		s.logger.Info().Msg(fmt.Sprintf("shutting down, got signal: %v", sig))
+		s.cancel()
+	}
+	s.shutdown()
+}
 
-outer:
-	for {
-		select {
-		case <-s.close:
-			// Waits for call to stop
-			s.health.SetServingStatus(s.cfg.ID, 2)
-			s.unregister()
-			s.closeClients()
-			s.stopServers()
-			break outer
-		case sig := <-sigch:
-			// Waits for signal to stop
-			s.logger.Info().Msg(fmt.Sprintf("shutting down, got signal: %v", sig))
-			s.health.SetServingStatus(s.cfg.ID, 2)
-			s.unregister()
-			s.closeClients()
-			s.stopServers()
-			break outer
-		default:
-			// keep on looping, non-blocking channel operations
-			time.Sleep(50 * time.Millisecond)
-			continue
+// shutdown stops the service in order: before_stop hooks, unregister from
+// discovery, drain servers, close clients, flush traces and after_stop hooks.
+// If it does not complete within ShutdownTimeout the process is forced to
+// exit.
+func (s *Service) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.cfg.mu.Lock()
+		s.cfg.running = false
+		s.cfg.mu.Unlock()
+		s.health.SetServingStatus(s.cfg.ID, 2)
+		if err := s.runHooks(ctx, hookBeforeStop); err != nil {
+			s.logger.Error().Msg(err.Error())
+		}
+		if err := s.unregister(); err != nil {
+			s.logger.Error().Msg(err.Error())
+		}
+		s.stopServers()
+		s.closeClients()
+		if err := s.cfg.Tracer.Shutdown(ctx); err != nil {
+			s.logger.Error().Msg(err.Error())
 		}
+		if err := s.runHooks(ctx, hookAfterStop); err != nil {
+			s.logger.Error().Msg(err.Error())
+		}
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.logger.Error().Msg(fmt.Sprintf("%s failed to stop within %v, forcing exit", s.Name(), s.cfg.ShutdownTimeout))
+		exit(1)
 	}
 }
 
+// closeClients closes all clients and waits for them to finish
 func (s *Service) closeClients() {
-	close(s.cfg.clientsCh)
-	// for _, clt := range s.cfg.Clients {
-	// 	// add go routine to WaitGroup
-	// 	s.wg.Add(1)
-	// 	go func(clt *client.Client) {
-	// 		defer s.wg.Done()
-	// 		clt.Close()
-	// 	}(clt)
-	// }
+	var wg sync.WaitGroup
+	for _, clt := range s.clients() {
+		wg.Add(1)
+		go func(clt *client.Client) {
+			defer wg.Done()
+			if err := clt.Close(); err != nil {
+				s.logger.Error().Msg(err.Error())
+			}
+		}(clt)
+	}
+	wg.Wait()
 }
 
+// stopServers stops all servers and waits for them to drain
 func (s *Service) stopServers() {
-	for _, srv := range s.cfg.Servers {
-		// add go routine to WaitGroup
-		s.wg.Add(1)
-		go func(s *Service, srv *server.Server) {
-			defer s.wg.Done()
+	var wg sync.WaitGroup
+	for _, srv := range s.servers() {
+		wg.Add(1)
+		go func(srv *server.Server) {
+			defer wg.Done()
 			srv.Stop()
-		}(s, srv)
+		}(srv)
 	}
+	wg.Wait()
 }
 
-// Call invoque's the methodName in the specific clientName initialize
-func Call(ctx context.Context, clientName, methodName string, args ...interface{}) (interface{}, error) {
-
+// Stub returns the gRPC stub of the client clientName, available in the pluto
+// service in ctx, as its concrete type T. Calls made through the returned stub
+// are checked at compile time and share the client connection.
+// e.g.
+//
+//	stub, err := pluto.Stub[pb.GreeterClient](ctx, "gopher")
+//	if err != nil { ... }
+//	res, err := stub.SayHello(ctx, &pb.HelloRequest{Name: "World"})
+func Stub[T any](ctx context.Context, clientName string) (T, error) {
 	c, ok := FromContext(ctx).Client(clientName)
 	if !ok {
-		return nil, fmt.Errorf("grpc client: %v not available", clientName)
+		var stub T
+		return stub, fmt.Errorf("grpc client: %v not available", clientName)
 	}
-	conn, err := c.Dial(client.Timeout(2 * time.Second))
-	if err != nil {
-		return nil, err
-	}
-	defer conn.Close()
-
-	inputs := make([]reflect.Value, len(args)+1)
-	// add context to the first position
-	inputs[0] = reflect.ValueOf(ctx)
-	for i := range args {
-		inputs[i+1] = reflect.ValueOf(args[i])
-	}
-
-	resp := reflect.ValueOf(c.Stub(conn)).MethodByName(methodName).Call(inputs)
-
-	if resp[1].Interface() != nil {
-		return nil, resp[1].Interface().(error)
-	}
-
-	return resp[0].Interface(), nil
+	return client.StubOf[T](c)
 }
 
-// CallWithCredentials invoque's the methodName in the specific clientName initialize
-func CallWithCredentials(ctx context.Context, clientName, methodName string, args ...interface{}) (interface{}, error) {
-
-	// Get token from context
+// Credentials returns a call option that forwards the bearer token available
+// in ctx to the server, to be used together with Stub
+// e.g.
+//
+//	creds, err := pluto.Credentials(ctx)
+//	if err != nil { ... }
+//	res, err := stub.SayGoodbye(ctx, in, creds)
+func Credentials(ctx context.Context) (grpc.CallOption, error) {
 	t, ok := jwt.TokenFromContext(ctx)
 	if !ok {
-		return "", fmt.Errorf("token not available in context")
+		return nil, errTokenNotAvailable
 	}
+	return client.Token(t), nil
+}
 
+// Call invoque's the methodName in the specific clientName initialize
+// Note: deprecated, please use Stub for compile time checked calls
+func Call(ctx context.Context, clientName, methodName string, args ...interface{}) (interface{}, error) {
 	c, ok := FromContext(ctx).Client(clientName)
 	if !ok {
 		return nil, fmt.Errorf("grpc client: %v not available", clientName)
 	}
+	conn, err := c.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return call(ctx, c.Stub(conn), methodName, args...)
+}
 
-	conn, err := c.DialWithCredentials(t, client.Timeout(2*time.Second))
+// CallWithCredentials invoque's the methodName in the specific clientName initialize
+// Note: deprecated, please use Stub together with Credentials
+func CallWithCredentials(ctx context.Context, clientName, methodName string, args ...interface{}) (interface{}, error) {
+	creds, err := Credentials(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
+	// args is copied, as it may share the backing array of the caller slice
+	return Call(ctx, clientName, methodName, append(append([]interface{}{}, args...), creds)...)
+}
 
+// call invokes methodName on stub by reflection, returning an error instead
+// of panicking when the method or its arguments do not match
+func call(ctx context.Context, stub interface{}, methodName string, args ...interface{}) (interface{}, error) {
+	m := reflect.ValueOf(stub).MethodByName(methodName)
+	if !m.IsValid() {
+		return nil, fmt.Errorf("grpc stub: %T has no method %v", stub, methodName)
+	}
 	inputs := make([]reflect.Value, len(args)+1)
 	// add context to the first position
 	inputs[0] = reflect.ValueOf(ctx)
 	for i := range args {
 		inputs[i+1] = reflect.ValueOf(args[i])
 	}
+	if err := validateInputs(m.Type(), inputs); err != nil {
+		return nil, fmt.Errorf("grpc stub: %T.%v %v", stub, methodName, err)
+	}
 
-	resp := reflect.ValueOf(c.Stub(conn)).MethodByName(methodName).Call(inputs)
+	resp := m.Call(inputs)
 
 	if resp[1].Interface() != nil {
 		return nil, resp[1].Interface().(error)
@@ -388,3 +647,27 @@ func CallWithCredentials(ctx context.Context, clientName, methodName string, arg
 
 	return resp[0].Interface(), nil
 }
+
+// validateInputs verifies inputs are assignable to the parameters of the
+// method type t
+func validateInputs(t reflect.Type, inputs []reflect.Value) error {
+	n := t.NumIn()
+	if t.IsVariadic() {
+		n--
+	}
+	if len(inputs) < n || (!t.IsVariadic() && len(inputs) > n) {
+		return fmt.Errorf("expects %d arguments, got %d", n, len(inputs))
+	}
+	for i, in := range inputs {
+		var pt reflect.Type
+		if i < n {
+			pt = t.In(i)
+		} else {
+			pt = t.In(n).Elem()
+		}
+		if !in.IsValid() || !in.Type().AssignableTo(pt) {
+			return fmt.Errorf("argument %d must be %v", i, pt)
+		}
+	}
+	return nil
+}
@@ -6,8 +6,13 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -18,10 +23,12 @@ import (
 
 	"github.com/aukbit/pluto/v6/auth/jwt"
 	"github.com/aukbit/pluto/v6/client"
+	"github.com/aukbit/pluto/v6/discovery"
 	"github.com/aukbit/pluto/v6/reply"
 	"github.com/aukbit/pluto/v6/server"
 	"github.com/aukbit/pluto/v6/server/router"
 	pb "github.com/aukbit/pluto/v6/test/proto"
+	"github.com/aukbit/pluto/v6/trace"
 	"google.golang.org/grpc"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
@@ -30,11 +37,15 @@ const serviceURL = "http://localhost:8081"
 const serviceCallURL = "http://localhost:8081/call"
 const serviceCallWithCredentialsURL = "http://localhost:8081/call-with-credentials"
 const serviceCallWithCredentialsWrapErrorURL = "http://localhost:8081/call-with-credentials-wrap-error"
+const serviceStubURL = "http://localhost:8081/stub"
 const healthURL = "http://localhost:9090/_health"
 const healthzURL = "http://localhost:9090/healthz"
 
 var serviceName = "gopher"
 
+// spans exported by the service started in TestMain
+var spans = trace.NewInMemoryExporter()
+
 func IndexHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	c, ok := FromContext(ctx).Client(serviceName)
@@ -63,6 +74,19 @@ func CallHandler(w http.ResponseWriter, r *http.Request) {
 	reply.Json(w, r, http.StatusOK, response.(*pb.HelloReply).GetMessage())
 }
 
+func StubHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	stub, err := Stub[pb.GreeterClient](ctx, serviceName)
+	if err != nil {
+		panic(err)
+	}
+	res, err := stub.SayHello(ctx, &pb.HelloRequest{Name: "Stub"})
+	if err != nil {
+		panic(err)
+	}
+	reply.Json(w, r, http.StatusOK, res.GetMessage())
+}
+
 func CallWithCredentialsHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	in := &pb.GoodbyeRequest{Name: "Traffic!"}
@@ -126,6 +150,7 @@ func TestMain(m *testing.M) {
 	mux := router.New()
 	mux.GET("/", IndexHandler)
 	mux.GET("/call", CallHandler)
+	mux.GET("/stub", StubHandler)
 	mux.GET("/call-with-credentials", jwt.WrapBearerToken(CallWithCredentialsHandler))
 	mux.Handle("GET", "/call-with-credentials-wrap-error", jwt.WrapBearerTokenErr(router.WrapErr(CallWithCredentialsHandlerWrapError)))
 	// Create pluto server
@@ -177,6 +202,7 @@ func TestMain(m *testing.M) {
 		Clients(cltGRPC),
 		HookAfterStart(fn1, fn2),
 		HealthAddr(":9090"),
+		Tracer(trace.NewTracer(spans)),
 	)
 
 	// if !testing.Short() {
@@ -241,6 +267,52 @@ func TestCallMethod(t *testing.T) {
 	assert.Equal(t, "Hello World", message)
 }
 
+func TestStub(t *testing.T) {
+	r, err := http.Get(serviceStubURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	var message string
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	assert.Equal(t, "Hello Stub", message)
+}
+
+func TestStubErrors(t *testing.T) {
+	clt := client.New(
+		client.Name(serviceName),
+		client.GRPCRegister(func(cc *grpc.ClientConn) interface{} {
+			return pb.NewGreeterClient(cc)
+		}),
+	)
+	defer clt.Close()
+	ctx := New(Clients(clt)).WithContext(context.Background())
+	// unknown client
+	_, err := Stub[pb.GreeterClient](ctx, "unknown")
+	assert.Equal(t, "grpc client: unknown not available", err.Error())
+	// stub of the wrong type
+	_, err = Stub[healthpb.HealthClient](ctx, serviceName)
+	assert.Equal(t, true, err != nil)
+	// reflection based calls return errors instead of panicking
+	_, err = Call(ctx, serviceName, "SayHelo", &pb.HelloRequest{})
+	assert.Equal(t, true, err != nil)
+	_, err = Call(ctx, serviceName, "SayHello", &pb.GoodbyeRequest{})
+	assert.Equal(t, true, err != nil)
+	// credentials are required in context
+	_, err = CallWithCredentials(ctx, serviceName, "SayGoodbye", &pb.GoodbyeRequest{})
+	assert.Equal(t, errTokenNotAvailable, err)
+	// the args of the caller are left untouched
+	args := make([]interface{}, 1, 2)
+	args[0] = &pb.GoodbyeRequest{}
+	sentinel := args[:2]
+	sentinel[1] = "unchanged"
+	CallWithCredentials(context.WithValue(ctx, jwt.TokenContextKey, "token"), "unknown", "SayGoodbye", args...)
+	assert.Equal(t, "unchanged", sentinel[1])
+}
+
 func TestCredentials(t *testing.T) {
 	time.Sleep(time.Second)
 	req, err := http.NewRequest("GET", serviceCallWithCredentialsURL, nil)
@@ -409,3 +481,303 @@ func TestHealth(t *testing.T) {
 		time.Sleep(time.Millisecond * 100)
 	}
 }
+
+func TestLifecycleHooks(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	hook := func(name string) HookFunc {
+		return func(ctx context.Context) error {
+			// service is available in hooks context
+			assert.Equal(t, "hooks_pluto", FromContext(ctx).Name())
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+	s := New(
+		Name("hooks"),
+		HealthAddr(":9091"),
+		HookAfterStop(hook("after_stop")),
+		HookBeforeStop(hook("before_stop")),
+		HookAfterStart(hook("after_start")),
+		HookBeforeStart(hook("before_start")),
+	)
+	done := make(chan error)
+	go func() {
+		done <- s.Run()
+	}()
+	time.Sleep(time.Millisecond * 500)
+	s.Stop()
+	assert.Equal(t, nil, <-done)
+	assert.Equal(t, []string{"before_start", "after_start", "before_stop", "after_stop"}, order)
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	code := make(chan int, 1)
+	exit = func(c int) { code <- c }
+	defer func() { exit = os.Exit }()
+	s := New(
+		Name("timeout"),
+		HealthAddr(":9092"),
+		ShutdownTimeout(100*time.Millisecond),
+		HookBeforeStop(func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(time.Millisecond * 100)
+			return ctx.Err()
+		}),
+	)
+	done := make(chan error)
+	go func() {
+		done <- s.Run()
+	}()
+	time.Sleep(time.Millisecond * 500)
+	s.Stop()
+	assert.Equal(t, 1, <-code)
+	<-done
+}
+
+func TestValidate(t *testing.T) {
+	s := New(
+		Name("invalid"),
+		HealthAddr(":9093"),
+		Servers(server.New(server.Name("a"), server.Addr(":9093"))),
+		Servers(server.New(server.Name("a"), server.Addr(":8093"))),
+		Servers(server.New(server.Name("b"), server.Addr(":8094"), server.TLSConfig("missing.crt", "missing.key"))),
+		Servers(server.New(server.Name("c"), server.Addr(":8095"), server.GRPCRegister(nil))),
+		Clients(client.New(client.Name("d"))),
+		ShutdownTimeout(0),
+	)
+	err := s.Run()
+	assert.Equal(t, true, err != nil)
+	for _, msg := range []string{
+		"duplicate server name a_server",
+		"a_server: port 9093 already used by health server",
+		"b_server: tls config: open missing.crt",
+		"c_server: grpc server requires a GRPCRegister function",
+		"d_client: target is required",
+		"d_client: grpc client requires a GRPCRegister function",
+		"shutdown timeout must be positive, got 0s",
+	} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("%q not reported in %v", msg, err)
+		}
+	}
+}
+
+// fakeDiscovery records registrations in memory
+type fakeDiscovery struct {
+	mu           sync.Mutex
+	checks       map[string]discovery.Check
+	deregistered []string
+}
+
+func (d *fakeDiscovery) IsAvailable() (bool, error)       { return true, nil }
+func (d *fakeDiscovery) Service(string) ([]string, error) { return nil, nil }
+func (d *fakeDiscovery) Unregister() error                { return nil }
+func (d *fakeDiscovery) Register(cfgs ...discovery.ConfigFunc) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	cfg := &discovery.Config{Services: discovery.Services{}, Checks: discovery.Checks{}}
+	for _, c := range cfgs {
+		c(cfg)
+	}
+	for _, c := range cfg.Checks {
+		d.checks[c.ServiceID] = c
+	}
+	return nil
+}
+func (d *fakeDiscovery) Deregister(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.deregistered = append(d.deregistered, id)
+	return nil
+}
+
+func TestDiscoveryRegistration(t *testing.T) {
+	d := &fakeDiscovery{checks: make(map[string]discovery.Check)}
+	srv := server.New(server.Name("registered"), server.Addr(":8096"), server.ID("srv_registered"))
+	s := New(
+		ID("plt_registered"),
+		Name("registered"),
+		HealthAddr(":9094"),
+		Discovery(d),
+		Servers(srv),
+	)
+	done := make(chan error)
+	go func() {
+		done <- s.Run()
+	}()
+	time.Sleep(time.Millisecond * 500)
+	d.mu.Lock()
+	assert.Equal(t, 2, len(d.checks))
+	assert.Equal(t, true, strings.HasSuffix(d.checks["plt_registered"].HTTP, ":9094/_health/pluto/registered_pluto"))
+	assert.Equal(t, true, strings.HasSuffix(d.checks["srv_registered"].HTTP, ":9094/_health/server/registered_server"))
+	d.mu.Unlock()
+	s.Stop()
+	assert.Equal(t, nil, <-done)
+	sort.Strings(d.deregistered)
+	assert.Equal(t, []string{"plt_registered", "srv_registered"}, d.deregistered)
+}
+
+func TestStopReleasesGoroutines(t *testing.T) {
+	// occupy the server port so it keeps retrying to start
+	ln, err := net.Listen("tcp", ":8097")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	before := runtime.NumGoroutine()
+	s := New(
+		Name("loop"),
+		HealthAddr(":9095"),
+		Servers(server.New(server.Name("busy"), server.Addr(":8097"))),
+	)
+	done := make(chan error)
+	go func() {
+		done <- s.Run()
+	}()
+	time.Sleep(time.Millisecond * 300)
+	s.Stop()
+	select {
+	case err := <-done:
+		assert.Equal(t, nil, err)
+	case <-time.After(time.Second * 2):
+		t.Fatal("service did not stop")
+	}
+	// allow exited goroutines to be reaped
+	time.Sleep(time.Millisecond * 100)
+	if n := runtime.NumGoroutine(); n > before+2 {
+		t.Errorf("%d goroutines still running after stop, %d before run", n, before)
+	}
+}
+
+func TestPushAndRemove(t *testing.T) {
+	s := New(
+		Name("push"),
+		HealthAddr(":9096"),
+	)
+	done := make(chan error)
+	go func() {
+		done <- s.Run()
+	}()
+	time.Sleep(time.Millisecond * 300)
+	mux := router.New()
+	mux.GET("/", func(w http.ResponseWriter, r *http.Request) {
+		reply.Json(w, r, http.StatusOK, "pushed")
+	})
+	err := s.Push(
+		Servers(server.New(server.Name("pushed"), server.Addr(":8098"), server.Mux(mux))),
+		Clients(client.New(
+			client.Name("pushed"),
+			client.Target("localhost:8098"),
+			client.GRPCRegister(func(cc *grpc.ClientConn) interface{} {
+				return healthpb.NewHealthClient(cc)
+			}),
+		)),
+	)
+	assert.Equal(t, nil, err)
+	time.Sleep(time.Millisecond * 300)
+	// pushed server is started and checked by the health server
+	r, err := http.Get("http://localhost:8098/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	r, err = http.Get("http://localhost:9096/_health/server/pushed_server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	_, ok := s.Client("pushed")
+	assert.Equal(t, true, ok)
+	// invalid pushes are rejected as a whole
+	err = s.Push(
+		Servers(server.New(server.Name("other"), server.Addr(":8099"))),
+		Servers(server.New(server.Name("clash"), server.Addr(":8098"))),
+	)
+	assert.Equal(t, "clash_server: port 8098 already used by pushed_server", err.Error())
+	_, ok = s.Server("other")
+	assert.Equal(t, false, ok)
+	// removed server is stopped
+	assert.Equal(t, nil, s.RemoveServer("pushed"))
+	assert.Equal(t, nil, s.RemoveClient("pushed"))
+	_, err = http.Get("http://localhost:8098/")
+	assert.Equal(t, true, err != nil)
+	r, err = http.Get("http://localhost:9096/_health/server/pushed_server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	assert.Equal(t, http.StatusNotFound, r.StatusCode)
+	assert.Equal(t, "server pushed_server not available", s.RemoveServer("pushed").Error())
+	s.Stop()
+	<-done
+}
+
+func TestMetrics(t *testing.T) {
+	// http route, grpc server and grpc client calls
+	r, err := http.Get(serviceStubURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	r, err = http.Get("http://localhost:9090/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+	for _, metric := range []string{
+		`pluto_http_requests_total{code="200",method="GET",route="/stub",server="gopher_http_server"}`,
+		`pluto_http_request_duration_seconds_count{code="200",method="GET",route="/stub",server="gopher_http_server"}`,
+		`pluto_grpc_server_handled_total{code="OK",method="/helloworld.Greeter/SayHello",server="gopher_grpc_server"}`,
+		`pluto_grpc_server_handling_seconds_count{code="OK",method="/helloworld.Greeter/SayHello",server="gopher_grpc_server"}`,
+		`pluto_grpc_client_handled_total{client="gopher_client",code="OK",method="/helloworld.Greeter/SayHello"}`,
+		`pluto_grpc_client_handling_seconds_count{client="gopher_client",code="OK",method="/helloworld.Greeter/SayHello"}`,
+	} {
+		if !strings.Contains(string(b), metric) {
+			t.Errorf("%s not exposed", metric)
+		}
+	}
+}
+
+func TestTracing(t *testing.T) {
+	spans.Reset()
+	req, err := http.NewRequest("GET", serviceStubURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	// the http span ends once the handler returns
+	time.Sleep(time.Millisecond * 100)
+	byKind := make(map[string]*trace.Span)
+	for _, s := range spans.Spans() {
+		byKind[fmt.Sprintf("%d %s", s.Kind, s.Name)] = s
+	}
+	httpSpan := byKind[fmt.Sprintf("%d GET /stub", trace.SpanKindServer)]
+	clientSpan := byKind[fmt.Sprintf("%d /helloworld.Greeter/SayHello", trace.SpanKindClient)]
+	grpcSpan := byKind[fmt.Sprintf("%d /helloworld.Greeter/SayHello", trace.SpanKindServer)]
+	if httpSpan == nil || clientSpan == nil || grpcSpan == nil {
+		t.Fatalf("missing spans %v", byKind)
+	}
+	// http -> grpc client -> grpc server share the incoming trace
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", httpSpan.SpanContext.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", httpSpan.ParentSpanID.String())
+	assert.Equal(t, httpSpan.SpanContext.TraceID, grpcSpan.SpanContext.TraceID)
+	assert.Equal(t, httpSpan.SpanContext.SpanID, clientSpan.ParentSpanID)
+	assert.Equal(t, clientSpan.SpanContext.SpanID, grpcSpan.ParentSpanID)
+	assert.Equal(t, "200", httpSpan.Attributes["http.status_code"])
+	assert.Equal(t, "OK", grpcSpan.Attributes["rpc.grpc.status_code"])
+}
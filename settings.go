@@ -0,0 +1,285 @@
+package pluto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aukbit/pluto/v6/client"
+	"github.com/aukbit/pluto/v6/common"
+	"github.com/aukbit/pluto/v6/server"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// envPrefix prefix of the environment variables overriding settings,
+// e.g. PLUTO_NAME, PLUTO_SERVER_<NAME>_ADDR or PLUTO_CLIENT_<NAME>_TARGET
+const envPrefix = "PLUTO_"
+
+// Settings declarative pluto service configuration with the settings of
+// each named server and client, e.g.
+//
+//	name: gopher
+//	health_addr: ":9090"
+//	servers:
+//	  http:
+//	    addr: ":8080"
+//	clients:
+//	  user:
+//	    target: "localhost:65060"
+type Settings struct {
+	ID              string                     `json:"id" yaml:"id"`
+	Name            string                     `json:"name" yaml:"name"`
+	Description     string                     `json:"description" yaml:"description"`
+	HealthAddr      string                     `json:"health_addr" yaml:"health_addr"`
+	ShutdownTimeout common.Duration            `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+	Servers         map[string]server.Settings `json:"servers" yaml:"servers"`
+	Clients         map[string]client.Settings `json:"clients" yaml:"clients"`
+}
+
+// LoadSettings reads the YAML (.yaml, .yml) or JSON (.json) files in order,
+// the settings set in later files overriding the ones of earlier files, e.g.
+// the addr of a server is kept when a later file only sets its read_timeout,
+// applies PLUTO_* environment overrides and validates the result.
+// PLUTO_SERVER_* and PLUTO_CLIENT_* variables must name known settings,
+// other PLUTO_* variables not naming a service setting are ignored.
+func LoadSettings(paths ...string) (*Settings, error) {
+	st := &Settings{
+		Servers: make(map[string]server.Settings),
+		Clients: make(map[string]client.Settings),
+	}
+	for _, p := range paths {
+		f, err := readFile(p)
+		if err != nil {
+			return nil, err
+		}
+		st.merge(f)
+	}
+	if err := st.readEnv(os.Environ()); err != nil {
+		return nil, err
+	}
+	if err := st.Validate(); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// readFile decodes the file p, unknown fields are reported as errors
+func readFile(p string) (*Settings, error) {
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	st := &Settings{}
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(b, st)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(st)
+	default:
+		return nil, fmt.Errorf("settings %s: unsupported file format", p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("settings %s: %v", p, err)
+	}
+	return st, nil
+}
+
+// merge overrides the settings of st with the ones set in o, field by field
+// for each server and client
+func (st *Settings) merge(o *Settings) {
+	for _, f := range []struct {
+		dst *string
+		v   string
+	}{
+		{&st.ID, o.ID},
+		{&st.Name, o.Name},
+		{&st.Description, o.Description},
+		{&st.HealthAddr, o.HealthAddr},
+	} {
+		if f.v != "" {
+			*f.dst = f.v
+		}
+	}
+	if o.ShutdownTimeout != 0 {
+		st.ShutdownTimeout = o.ShutdownTimeout
+	}
+	for name, srv := range o.Servers {
+		s := st.Servers[name]
+		s.Merge(srv)
+		st.Servers[name] = s
+	}
+	for name, clt := range o.Clients {
+		c := st.Clients[name]
+		c.Merge(clt)
+		st.Clients[name] = c
+	}
+}
+
+// readEnv applies the PLUTO_* variables in env, formatted as key=value
+func (st *Settings) readEnv(env []string) error {
+	sort.Strings(env)
+	for _, kv := range env {
+		i := strings.Index(kv, "=")
+		if i == -1 || !strings.HasPrefix(kv, envPrefix) {
+			continue
+		}
+		key, value := strings.ToLower(kv[len(envPrefix):i]), kv[i+1:]
+		var err error
+		switch {
+		case strings.HasPrefix(key, "server_"):
+			err = st.setServer(key[len("server_"):], value)
+		case strings.HasPrefix(key, "client_"):
+			err = st.setClient(key[len("client_"):], value)
+		default:
+			err = st.set(key, value)
+		}
+		if err != nil {
+			return fmt.Errorf("settings %s: %v", kv[:i], err)
+		}
+	}
+	return nil
+}
+
+// set assigns value to the service setting key, if any
+func (st *Settings) set(key, value string) error {
+	switch key {
+	case "id":
+		st.ID = value
+	case "name":
+		st.Name = value
+	case "description":
+		st.Description = value
+	case "health_addr":
+		st.HealthAddr = value
+	case "shutdown_timeout":
+		return st.ShutdownTimeout.Set(value)
+	}
+	// other variables sharing the prefix, e.g. PLUTO_HOME, are not settings
+	return nil
+}
+
+// setServer assigns value to the setting of the server named in key,
+// e.g. "http_addr" or "my_grpc_read_timeout"
+func (st *Settings) setServer(key, value string) error {
+	for _, p := range splitEnvKey(key) {
+		srv := st.Servers[p[0]]
+		err := srv.Set(p[1], value)
+		if err == server.ErrUnknownSetting {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		st.Servers[p[0]] = srv
+		return nil
+	}
+	return server.ErrUnknownSetting
+}
+
+// setClient assigns value to the setting of the client named in key,
+// e.g. "user_target"
+func (st *Settings) setClient(key, value string) error {
+	for _, p := range splitEnvKey(key) {
+		clt := st.Clients[p[0]]
+		err := clt.Set(p[1], value)
+		if err == client.ErrUnknownSetting {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		st.Clients[p[0]] = clt
+		return nil
+	}
+	return client.ErrUnknownSetting
+}
+
+// splitEnvKey returns the possible name and setting key pairs of key, since
+// both may contain underscores, shortest setting keys first
+func splitEnvKey(key string) [][2]string {
+	var out [][2]string
+	for i := strings.LastIndex(key, "_"); i > 0; i = strings.LastIndex(key[:i], "_") {
+		out = append(out, [2]string{key[:i], key[i+1:]})
+	}
+	return out
+}
+
+// Validate verifies every setting, reporting all invalid ones at once
+func (st *Settings) Validate() error {
+	var msgs []string
+	if st.HealthAddr != "" {
+		if _, err := common.Port(st.HealthAddr); err != nil {
+			msgs = append(msgs, fmt.Sprintf("health_addr: %v", err))
+		}
+	}
+	if st.ShutdownTimeout < 0 {
+		msgs = append(msgs, "shutdown_timeout: must not be negative")
+	}
+	for _, name := range sortedKeys(st.Servers) {
+		srv := st.Servers[name]
+		for _, err := range srv.Validate() {
+			msgs = append(msgs, fmt.Sprintf("servers.%s: %v", name, err))
+		}
+	}
+	for _, name := range sortedKeys(st.Clients) {
+		clt := st.Clients[name]
+		for _, err := range clt.Validate() {
+			msgs = append(msgs, fmt.Sprintf("clients.%s: %v", name, err))
+		}
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("invalid settings: %s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// Options returns the service options equivalent to the settings
+func (st *Settings) Options() []Option {
+	var opts []Option
+	if st.ID != "" {
+		opts = append(opts, ID(st.ID))
+	}
+	if st.Name != "" {
+		opts = append(opts, Name(st.Name))
+	}
+	if st.Description != "" {
+		opts = append(opts, Description(st.Description))
+	}
+	if st.HealthAddr != "" {
+		opts = append(opts, HealthAddr(st.HealthAddr))
+	}
+	if st.ShutdownTimeout != 0 {
+		opts = append(opts, ShutdownTimeout(st.ShutdownTimeout.Duration()))
+	}
+	return opts
+}
+
+// ServerOptions returns the options of the server name, to be combined with
+// the ones only available in code such as server.Mux or server.GRPCRegister
+func (st *Settings) ServerOptions(name string) []server.Option {
+	srv := st.Servers[name]
+	return srv.Options(name)
+}
+
+// ClientOptions returns the options of the client name, to be combined with
+// the ones only available in code such as client.GRPCRegister
+func (st *Settings) ClientOptions(name string) []client.Option {
+	clt := st.Clients[name]
+	return clt.Options(name)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
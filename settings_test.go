@@ -0,0 +1,127 @@
+package pluto
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aukbit/pluto/v6/client"
+	"github.com/aukbit/pluto/v6/server"
+	"github.com/paulormart/assert"
+)
+
+func writeSettings(t *testing.T, name, content string) string {
+	p := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(p, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoadSettings(t *testing.T) {
+	y := writeSettings(t, "pluto.yaml", `
+name: gopher
+health_addr: ":9191"
+shutdown_timeout: 5s
+servers:
+  http:
+    addr: ":8181"
+    read_timeout: 2s
+clients:
+  user:
+    target: "localhost:65061"
+`)
+	j := writeSettings(t, "pluto.json", `{"description": "from json", "servers": {"grpc": {"addr": ":65051"}}}`)
+	t.Setenv("PLUTO_SERVER_HTTP_WRITE_TIMEOUT", "3s")
+	t.Setenv("PLUTO_SERVER_MY_GRPC_DRAIN_TIMEOUT", "1s")
+	t.Setenv("PLUTO_CLIENT_USER_TARGET", "user:65061")
+	t.Setenv("PLUTO_NAME", "gopher_env")
+	t.Setenv("PLUTO_HOME", "/opt/pluto")
+
+	st, err := LoadSettings(y, j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "gopher_env", st.Name)
+	assert.Equal(t, "from json", st.Description)
+	assert.Equal(t, 5*time.Second, st.ShutdownTimeout.Duration())
+	assert.Equal(t, ":8181", st.Servers["http"].Addr)
+	assert.Equal(t, 2*time.Second, st.Servers["http"].ReadTimeout.Duration())
+	assert.Equal(t, 3*time.Second, st.Servers["http"].WriteTimeout.Duration())
+	assert.Equal(t, ":65051", st.Servers["grpc"].Addr)
+	assert.Equal(t, time.Second, st.Servers["my_grpc"].DrainTimeout.Duration())
+	assert.Equal(t, "user:65061", st.Clients["user"].Target)
+
+	s := New(st.Options()...)
+	assert.Equal(t, "gopher_env_pluto", s.Name())
+	assert.Equal(t, ":9191", s.cfg.HealthAddr)
+	srv := server.New(st.ServerOptions("http")...)
+	assert.Equal(t, "http_server", srv.Name())
+	clt := client.New(st.ClientOptions("user")...)
+	assert.Equal(t, "user_client", clt.Name())
+}
+
+func TestLoadSettingsLayers(t *testing.T) {
+	base := writeSettings(t, "base.yaml", `
+name: gopher
+servers:
+  http:
+    addr: ":8181"
+    read_timeout: 2s
+clients:
+  user:
+    target: "localhost:65061"
+`)
+	y := writeSettings(t, "override.yaml", `
+servers:
+  http:
+    write_timeout: 3s
+clients:
+  user:
+    timeout: 1s
+`)
+	j := writeSettings(t, "override.json", `{"servers": {"http": {"read_timeout": "4s"}}}`)
+
+	st, err := LoadSettings(base, y, j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "gopher", st.Name)
+	assert.Equal(t, ":8181", st.Servers["http"].Addr)
+	assert.Equal(t, 4*time.Second, st.Servers["http"].ReadTimeout.Duration())
+	assert.Equal(t, 3*time.Second, st.Servers["http"].WriteTimeout.Duration())
+	assert.Equal(t, "localhost:65061", st.Clients["user"].Target)
+	assert.Equal(t, time.Second, st.Clients["user"].Timeout.Duration())
+}
+
+func TestLoadSettingsErrors(t *testing.T) {
+	y := writeSettings(t, "pluto.yml", `
+health_addr: "9090"
+servers:
+  http:
+    addr: ":bogus"
+    read_timeout: -1s
+    tls_cert_file: server.crt
+`)
+	_, err := LoadSettings(y)
+	assert.Equal(t, true, err != nil)
+	for _, msg := range []string{
+		"health_addr",
+		"servers.http: address :bogus: invalid port",
+		"servers.http: read_timeout must not be negative",
+		"servers.http: tls_cert_file and tls_key_file must be set together",
+	} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("%q not reported in %v", msg, err)
+		}
+	}
+	// unknown fields
+	_, err = LoadSettings(writeSettings(t, "pluto.yaml", "servers:\n  http:\n    port: 80\n"))
+	assert.Equal(t, true, err != nil)
+	// unknown environment settings
+	t.Setenv("PLUTO_SERVER_HTTP_PORT", "80")
+	_, err = LoadSettings()
+	assert.Equal(t, "settings PLUTO_SERVER_HTTP_PORT: unknown server setting", err.Error())
+}
@@ -0,0 +1,51 @@
+package trace
+
+import (
+	"context"
+	"sync"
+)
+
+// Exporter receives the spans once they end
+type Exporter interface {
+	// ExportSpan is called on every ended span and must not block
+	ExportSpan(span *Span)
+	// Shutdown flushes any pending span and releases the exporter
+	Shutdown(ctx context.Context) error
+}
+
+// InMemoryExporter keeps ended spans in memory, useful for tests
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// NewInMemoryExporter returns an empty in-memory exporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan keeps span
+func (e *InMemoryExporter) ExportSpan(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// Shutdown does nothing, spans are kept
+func (e *InMemoryExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Spans returns the spans exported so far in the order they ended
+func (e *InMemoryExporter) Spans() []*Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*Span{}, e.spans...)
+}
+
+// Reset drops the spans exported so far
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
@@ -0,0 +1,245 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	otlpBatchSize     = 512
+	otlpMaxQueueSize  = 2048
+	otlpFlushInterval = 5 * time.Second
+)
+
+// OTLPExporter sends spans in batches to an OpenTelemetry collector using
+// OTLP over HTTP with JSON encoding. Spans are dropped if the queue is full.
+type OTLPExporter struct {
+	url     string
+	service string
+	client  *http.Client
+	logger  zerolog.Logger // logs spans failing to be sent in background
+
+	mu    sync.Mutex
+	queue []*Span
+
+	flush   chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// OTLPOption is used to set options for the OTLP exporter.
+type OTLPOption interface {
+	apply(*OTLPExporter)
+}
+
+// otlpOptionFunc wraps a func so it satisfies the OTLPOption interface.
+type otlpOptionFunc func(*OTLPExporter)
+
+func (f otlpOptionFunc) apply(e *OTLPExporter) {
+	f(e)
+}
+
+// OTLPLogger sets the logger of the errors sending spans in background,
+// e.g. the service logger. Errors are not logged by default.
+func OTLPLogger(l zerolog.Logger) OTLPOption {
+	return otlpOptionFunc(func(e *OTLPExporter) {
+		e.logger = l
+	})
+}
+
+// NewOTLPExporter returns an exporter sending the spans of serviceName to
+// the collector at endpoint, e.g. http://localhost:4318
+func NewOTLPExporter(endpoint, serviceName string, opts ...OTLPOption) *OTLPExporter {
+	e := &OTLPExporter{
+		url:     strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		service: serviceName,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		logger:  zerolog.Nop(),
+		flush:   make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt.apply(e)
+	}
+	go e.loop()
+	return e
+}
+
+// ExportSpan queues span to be sent with the next batch
+func (e *OTLPExporter) ExportSpan(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.queue) >= otlpMaxQueueSize {
+		return
+	}
+	e.queue = append(e.queue, span)
+	if len(e.queue) >= otlpBatchSize {
+		select {
+		case e.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush sends the queued spans to the collector
+func (e *OTLPExporter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	spans := e.queue
+	e.queue = nil
+	e.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := e.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("otlp exporter: %s responded %s", e.url, res.Status)
+	}
+	return nil
+}
+
+// Shutdown stops the background flushing and sends the queued spans
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.once.Do(func() { close(e.done) })
+	<-e.stopped
+	return e.Flush(ctx)
+}
+
+// loop flushes the queue periodically or when a batch is full
+func (e *OTLPExporter) loop() {
+	defer close(e.stopped)
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+		case <-e.flush:
+		}
+		if err := e.Flush(context.Background()); err != nil {
+			e.logger.Error().Err(err).Str("endpoint", e.url).Str("service", e.service).Msg("otlp export failed")
+		}
+	}
+}
+
+// --- OTLP JSON encoding
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	TraceState        string         `json:"traceState,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    StatusCode `json:"code,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+func (e *OTLPExporter) request(spans []*Span) otlpRequest {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		o := otlpSpan{
+			TraceID:           s.SpanContext.TraceID.String(),
+			SpanID:            s.SpanContext.SpanID.String(),
+			TraceState:        s.SpanContext.TraceState,
+			Name:              s.Name,
+			Kind:              s.Kind,
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+			Attributes:        attributes(s.Attributes),
+			Status:            otlpStatus{Code: s.StatusCode, Message: s.StatusMessage},
+		}
+		if s.ParentSpanID.IsValid() {
+			o.ParentSpanID = s.ParentSpanID.String()
+		}
+		out = append(out, o)
+	}
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: attributes(map[string]string{"service.name": e.service}),
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/aukbit/pluto/v6/trace"},
+				Spans: out,
+			}},
+		}},
+	}
+}
+
+// attributes returns m as OTLP key values sorted by key
+func attributes(m map[string]string) []otlpKeyValue {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, otlpKeyValue{Key: k, Value: otlpValue{StringValue: m[k]}})
+	}
+	return kvs
+}
@@ -0,0 +1,128 @@
+package trace
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// W3C Trace Context header names
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+var errInvalidTraceparent = errors.New("invalid traceparent")
+
+// Traceparent returns sc formatted as a version 00 traceparent header
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent parses a traceparent header value
+// e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func ParseTraceparent(s string) (SpanContext, error) {
+	var sc SpanContext
+	// later versions may append fields after the flags
+	if len(s) < 55 || (len(s) > 55 && s[55] != '-') {
+		return sc, errInvalidTraceparent
+	}
+	if s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return sc, errInvalidTraceparent
+	}
+	version, err := decodeHex(s[0:2])
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(s) != 55) {
+		return sc, errInvalidTraceparent
+	}
+	traceID, err := decodeHex(s[3:35])
+	if err != nil {
+		return sc, errInvalidTraceparent
+	}
+	spanID, err := decodeHex(s[36:52])
+	if err != nil {
+		return sc, errInvalidTraceparent
+	}
+	flags, err := decodeHex(s[53:55])
+	if err != nil {
+		return sc, errInvalidTraceparent
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return sc, errInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// Inject sets the traceparent and tracestate headers of the span in ctx
+func Inject(ctx context.Context, h http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	h.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		h.Set(TracestateHeader, sc.TraceState)
+	}
+}
+
+// Extract returns a copy of ctx holding the remote span context carried by
+// the traceparent and tracestate headers, or ctx if there is none valid
+func Extract(ctx context.Context, h http.Header) context.Context {
+	return extract(ctx, h.Get(TraceparentHeader), h.Get(TracestateHeader))
+}
+
+// InjectMetadata sets the traceparent and tracestate keys of the span in ctx
+func InjectMetadata(ctx context.Context, md metadata.MD) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	md.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		md.Set(TracestateHeader, sc.TraceState)
+	}
+}
+
+// ExtractMetadata returns a copy of ctx holding the remote span context
+// carried by the traceparent and tracestate keys, or ctx if there is none
+// valid
+func ExtractMetadata(ctx context.Context, md metadata.MD) context.Context {
+	return extract(ctx, first(md.Get(TraceparentHeader)), first(md.Get(TracestateHeader)))
+}
+
+// --- Helper functions
+
+func extract(ctx context.Context, traceparent, tracestate string) context.Context {
+	if traceparent == "" {
+		return ctx
+	}
+	sc, err := ParseTraceparent(traceparent)
+	if err != nil {
+		return ctx
+	}
+	sc.TraceState = tracestate
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// decodeHex decodes lowercase hex only, as required by the specification
+func decodeHex(s string) ([]byte, error) {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return nil, errInvalidTraceparent
+		}
+	}
+	return hex.DecodeString(s)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
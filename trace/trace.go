@@ -0,0 +1,236 @@
+// Package trace implements distributed tracing for pluto servers and clients.
+// Spans are propagated between services with the W3C Trace Context headers
+// traceparent and tracestate, and handed over to an Exporter once they end.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// TraceID identifies a trace, shared by all its spans
+type TraceID [16]byte
+
+// String returns the lowercase hex encoding of the trace id
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// IsValid reports whether the trace id has at least one non-zero byte
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// String returns the lowercase hex encoding of the span id
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// IsValid reports whether the span id has at least one non-zero byte
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// FlagsSampled is the trace flag set when the trace is sampled
+const FlagsSampled = byte(0x01)
+
+// SpanContext is the part of a span propagated to other services
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+}
+
+// IsValid reports whether both trace and span ids are valid
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// SpanKind describes the relationship of a span to its parent
+type SpanKind int
+
+// Span kinds, values match the OTLP ones
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// StatusCode of a span, values match the OTLP ones
+type StatusCode int
+
+// Span status codes
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// Span is a single operation within a trace. Fields must not be modified
+// once the span has ended.
+type Span struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	ParentSpanID  SpanID
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    map[string]string
+	StatusCode    StatusCode
+	StatusMessage string
+
+	mu     sync.Mutex
+	ended  bool
+	tracer *Tracer
+}
+
+// SetAttribute sets the attribute key to value
+func (s *Span) SetAttribute(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.Attributes[key] = value
+}
+
+// SetStatus sets the span status code and message
+func (s *Span) SetStatus(code StatusCode, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.StatusCode = code
+	s.StatusMessage = msg
+}
+
+// End records the span end time and exports it. Only the first call has
+// any effect.
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+	if s.tracer != nil && s.tracer.exporter != nil {
+		s.tracer.exporter.ExportSpan(s)
+	}
+}
+
+// Tracer creates spans and hands them over to its exporter once they end.
+// A nil Tracer, or one without exporter, still creates and propagates spans
+// so trace ids are available in logs.
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer returns a tracer exporting spans to e
+func NewTracer(e Exporter) *Tracer {
+	return &Tracer{exporter: e}
+}
+
+// Start creates a span named name, child of the span or remote span context
+// available in ctx, and returns a copy of ctx holding the new span
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	span := &Span{
+		Name:       name,
+		Kind:       kind,
+		StartTime:  time.Now(),
+		Attributes: make(map[string]string),
+		tracer:     t,
+	}
+	parent := SpanContextFromContext(ctx)
+	if parent.IsValid() {
+		span.SpanContext = parent
+		span.ParentSpanID = parent.SpanID
+	} else {
+		span.SpanContext.TraceID = newTraceID()
+		span.SpanContext.Flags = FlagsSampled
+	}
+	span.SpanContext.SpanID = newSpanID()
+	return ContextWithSpan(ctx, span), span
+}
+
+// Shutdown flushes and stops the tracer exporter
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil || t.exporter == nil {
+		return nil
+	}
+	return t.exporter.Shutdown(ctx)
+}
+
+// --- Context
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// ContextWithSpan returns a copy of ctx holding span
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span in ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	if s, ok := ctx.Value(spanKey{}).(*Span); ok {
+		return s
+	}
+	return nil
+}
+
+// ContextWithRemoteSpanContext returns a copy of ctx holding sc, received
+// from another service, as parent of the spans started from it
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context of the span in ctx or,
+// if there is none, the remote span context in ctx
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if s := SpanFromContext(ctx); s != nil {
+		return s.SpanContext
+	}
+	if sc, ok := ctx.Value(remoteKey{}).(SpanContext); ok {
+		return sc
+	}
+	return SpanContext{}
+}
+
+// Logger returns l with the trace and span ids of the span in ctx
+func Logger(ctx context.Context, l zerolog.Logger) zerolog.Logger {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return l
+	}
+	return l.With().
+		Str("trace_id", sc.TraceID.String()).
+		Str("span_id", sc.SpanID.String()).Logger()
+}
+
+// --- Helper functions
+
+func newTraceID() (t TraceID) {
+	for !t.IsValid() {
+		rand.Read(t[:])
+	}
+	return t
+}
+
+func newSpanID() (s SpanID) {
+	for !s.IsValid() {
+		rand.Read(s[:])
+	}
+	return s
+}
@@ -0,0 +1,152 @@
+package trace_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aukbit/pluto/v6/trace"
+	"github.com/paulormart/assert"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/metadata"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := trace.ParseTraceparent(traceparent)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.Equal(t, trace.FlagsSampled, sc.Flags)
+	assert.Equal(t, traceparent, sc.Traceparent())
+	// later versions may carry more fields
+	_, err = trace.ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	assert.Equal(t, nil, err)
+	for _, v := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+	} {
+		if _, err := trace.ParseTraceparent(v); err == nil {
+			t.Errorf("%q should be invalid", v)
+		}
+	}
+}
+
+func TestStart(t *testing.T) {
+	exp := trace.NewInMemoryExporter()
+	tr := trace.NewTracer(exp)
+	// root span starts a new trace
+	ctx, root := tr.Start(context.Background(), "root", trace.SpanKindServer)
+	assert.Equal(t, true, root.SpanContext.IsValid())
+	assert.Equal(t, false, root.ParentSpanID.IsValid())
+	// child span shares the trace
+	_, child := tr.Start(ctx, "child", trace.SpanKindClient)
+	assert.Equal(t, root.SpanContext.TraceID, child.SpanContext.TraceID)
+	assert.Equal(t, root.SpanContext.SpanID, child.ParentSpanID)
+	child.End()
+	root.End()
+	root.End()
+	spans := exp.Spans()
+	assert.Equal(t, 2, len(spans))
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, "root", spans[1].Name)
+	// nil tracer still propagates
+	_, span := (*trace.Tracer)(nil).Start(ctx, "untraced", trace.SpanKindInternal)
+	span.End()
+	assert.Equal(t, root.SpanContext.TraceID, span.SpanContext.TraceID)
+}
+
+func TestPropagation(t *testing.T) {
+	h := http.Header{}
+	h.Set("traceparent", traceparent)
+	h.Set("tracestate", "congo=t61rcWkgMzE")
+	ctx := trace.Extract(context.Background(), h)
+	_, span := trace.NewTracer(nil).Start(ctx, "server", trace.SpanKindServer)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", span.ParentSpanID.String())
+	assert.Equal(t, "congo=t61rcWkgMzE", span.SpanContext.TraceState)
+	// metadata carries the new span
+	md := metadata.MD{}
+	trace.InjectMetadata(trace.ContextWithSpan(ctx, span), md)
+	ctx = trace.ExtractMetadata(context.Background(), md)
+	sc := trace.SpanContextFromContext(ctx)
+	assert.Equal(t, span.SpanContext, sc)
+	// invalid headers are ignored
+	h.Set("traceparent", "invalid")
+	ctx = trace.Extract(context.Background(), h)
+	assert.Equal(t, false, trace.SpanContextFromContext(ctx).IsValid())
+}
+
+func TestOTLPExporter(t *testing.T) {
+	received := make(chan map[string]interface{}, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		received <- body
+	}))
+	defer ts.Close()
+	exp := trace.NewOTLPExporter(ts.URL, "gopher")
+	ctx := trace.Extract(context.Background(), http.Header{"Traceparent": {traceparent}})
+	_, span := trace.NewTracer(exp).Start(ctx, "GET /home/:id", trace.SpanKindServer)
+	span.SetAttribute("http.method", "GET")
+	span.End()
+	assert.Equal(t, nil, exp.Shutdown(context.Background()))
+	body := <-received
+	rs := body["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	s := rs["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", s["traceId"])
+	assert.Equal(t, "00f067aa0ba902b7", s["parentSpanId"])
+	assert.Equal(t, "GET /home/:id", s["name"])
+	assert.Equal(t, float64(trace.SpanKindServer), s["kind"])
+}
+
+func TestOTLPExporterErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	logs := make(chan string, 1)
+	logger := zerolog.New(writerFunc(func(p []byte) (int, error) {
+		select {
+		case logs <- string(p):
+		default:
+		}
+		return len(p), nil
+	}))
+	exp := trace.NewOTLPExporter(ts.URL, "gopher", trace.OTLPLogger(logger))
+	defer exp.Shutdown(context.Background())
+	// a full batch is sent in background
+	tracer := trace.NewTracer(exp)
+	for i := 0; i < 512; i++ {
+		_, span := tracer.Start(context.Background(), "GET /home", trace.SpanKindServer)
+		span.End()
+	}
+	select {
+	case l := <-logs:
+		assert.Equal(t, true, strings.Contains(l, `"message":"otlp export failed"`))
+		assert.Equal(t, true, strings.Contains(l, "503 Service Unavailable"))
+	case <-time.After(time.Second):
+		t.Fatal("export error not logged")
+	}
+}
+
+// writerFunc is an io.Writer calling itself
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}